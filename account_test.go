@@ -0,0 +1,65 @@
+package seacle
+
+import (
+	"database/sql"
+)
+
+// Account has a secret column. The table does not exist, so that every
+// query fails with its arguments.
+type Account struct {
+	ID       int64  `db:"id,primary,auto_increment"`
+	Name     string `db:"name"`
+	Password string `db:"password,secret"`
+}
+
+func (p *Account) Table() string {
+	return "no_account"
+}
+
+func (p *Account) Columns() []string {
+	return []string{"no_account.id", "no_account.name", "no_account.password"}
+}
+
+func (p *Account) PrimaryKeys() []string {
+	return []string{"id"}
+}
+
+func (p *Account) PrimaryValues() []interface{} {
+	return []interface{}{p.ID}
+}
+
+func (p *Account) ValueColumns() []string {
+	return []string{"name", "password"}
+}
+
+func (p *Account) Values() []interface{} {
+	return []interface{}{p.Name, p.Password}
+}
+
+func (p *Account) SecretColumns() []string {
+	return []string{"password"}
+}
+
+func (p *Account) AutoIncrementColumn() string {
+	return "id"
+}
+
+func (p *Account) Scan(r RowScanner) error {
+	var arg0 int64
+	var arg1 string
+	var arg2 string
+
+	err := r.Scan(&arg0, &arg1, &arg2)
+	if err == sql.ErrNoRows {
+		return err
+	} else if err != nil {
+		// something wrong
+		return err
+	}
+
+	p.ID = arg0
+	p.Name = arg1
+	p.Password = arg2
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package seacle
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+)
+
+type inArg struct {
+	value interface{}
+}
+
+type literalArg struct {
+	value interface{}
+}
+
+// In marks v to be expanded into "?,?,?" even if it would be bound as a
+// single value otherwise (e.g. a slice implementing driver.Valuer).
+func In(v interface{}) interface{} {
+	return inArg{value: v}
+}
+
+// Literal marks v to be bound as a single value without expansion. The
+// result is meaningful only for seacle functions.
+func Literal(v interface{}) interface{} {
+	return literalArg{value: v}
+}
+
+var (
+	literalTypesMu sync.RWMutex
+	literalTypes   = map[reflect.Type]bool{}
+)
+
+// RegisterLiteralType registers the type of sample so that arguments of the
+// type are never expanded, as if they were wrapped by Literal. This is for
+// slice types which a driver binds as a single value without implementing
+// driver.Valuer.
+func RegisterLiteralType(sample interface{}) {
+	literalTypesMu.Lock()
+	literalTypes[reflect.TypeOf(sample)] = true
+	literalTypesMu.Unlock()
+}
+
+var valuerIf = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// isExpandable reports whether v is a slice which should be expanded into
+// "?,?,?". []byte (including json.RawMessage), driver.Valuer and types
+// registered by RegisterLiteralType are bound as a single value.
+func isExpandable(v interface{}) bool {
+	tp := reflect.TypeOf(v)
+	if tp == nil || tp.Kind() != reflect.Slice {
+		return false
+	}
+	if tp.Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+	if tp.Implements(valuerIf) {
+		return false
+	}
+
+	literalTypesMu.RLock()
+	defer literalTypesMu.RUnlock()
+	return !literalTypes[tp]
+}
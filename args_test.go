@@ -0,0 +1,66 @@
+package seacle
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stringArray is a slice implementing driver.Valuer like pq.StringArray.
+type stringArray []string
+
+func (a stringArray) Value() (driver.Value, error) {
+	return "{" + strings.Join(a, ",") + "}", nil
+}
+
+type registeredSlice []int
+
+func TestExpandPlaceholderLiteral(t *testing.T) {
+	RegisterLiteralType(registeredSlice{})
+
+	tests := []struct {
+		arg      interface{}
+		expected string
+		exargs   []interface{}
+	}{
+		{[]int{1, 2}, "WHERE v IN (?,?)", []interface{}{1, 2}},
+		{[]byte("blob"), "WHERE v IN (?)", []interface{}{[]byte("blob")}},
+		{json.RawMessage(`{"a":1}`), "WHERE v IN (?)", []interface{}{json.RawMessage(`{"a":1}`)}},
+		{stringArray{"a", "b"}, "WHERE v IN (?)", []interface{}{stringArray{"a", "b"}}},
+		{registeredSlice{1, 2}, "WHERE v IN (?)", []interface{}{registeredSlice{1, 2}}},
+		{Literal([]int{1, 2}), "WHERE v IN (?)", []interface{}{[]int{1, 2}}},
+		{In(stringArray{"a", "b"}), "WHERE v IN (?,?)", []interface{}{"a", "b"}},
+		{In([2]int{1, 2}), "WHERE v IN (?,?)", []interface{}{1, 2}},
+	}
+
+	for _, tt := range tests {
+		query, exargs, err := expandPlaceholder(Generic, "WHERE v IN (?)", tt.arg)
+		if err != nil {
+			t.Errorf("%T: unexpected error: %s", tt.arg, err)
+		}
+		if query != tt.expected {
+			t.Errorf("%T: unexpected query: %s", tt.arg, query)
+		}
+		if !reflect.DeepEqual(exargs, tt.exargs) {
+			t.Errorf("%T: unexpected args: %v", tt.arg, exargs)
+		}
+	}
+
+	_, _, err := expandPlaceholder(Generic, "WHERE v IN (?)", In(1))
+	if err == nil || err.Error() != `args[0] given to In is not slice: int: query="WHERE v IN (?)"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// []byte reaches the driver as a blob
+	var length int
+	err = QueryRowContext(context.Background(), db, `SELECT length(?)`, []byte("blob!")).Scan(&length)
+	if err != nil {
+		t.Fatalf("failed to query: %s", err)
+	}
+	if length != 5 {
+		t.Errorf("unexpected length: %d", length)
+	}
+}
@@ -0,0 +1,158 @@
+package seacle
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Commenter configures sqlcommenter style comments appended to statements
+// built by seacle, like
+//
+//	SELECT ... FROM person WHERE id = ? /*app='myapp',caller='main.handler',route='%2Fusers'*/
+//
+// Keys and values are percent-encoded, so they can never close the comment.
+// Raw queries given to QueryContext, QueryRowContext and QueryRow are not
+// annotated.
+type Commenter struct {
+	// App is added as the "app" tag when not empty.
+	App string
+	// Caller adds the "caller" tag, the first function outside seacle in
+	// the call stack.
+	Caller bool
+}
+
+var (
+	commenterMu sync.RWMutex
+	commenter   *Commenter
+)
+
+// SetCommenter enables comments on statements. nil disables them, which is
+// the default.
+func SetCommenter(c *Commenter) {
+	commenterMu.Lock()
+	commenter = c
+	commenterMu.Unlock()
+}
+
+type commentKey struct{}
+
+// commentTags is the value of commentKey. nil means comments are disabled
+// for the context.
+type commentTags struct {
+	tags map[string]string
+}
+
+// WithCommentTag returns a Context which adds key=value to comments on
+// statements issued with it, e.g. WithCommentTag(ctx, "route", "/users").
+func WithCommentTag(ctx Context, key, value string) Context {
+	tags := map[string]string{}
+	if v, ok := ctx.Value(commentKey{}).(*commentTags); ok {
+		if v == nil {
+			// disabled
+			return ctx
+		}
+		for k, v := range v.tags {
+			tags[k] = v
+		}
+	}
+	tags[key] = value
+	return context.WithValue(ctx, commentKey{}, &commentTags{tags: tags})
+}
+
+// WithoutComment returns a Context which disables comments on statements
+// issued with it.
+func WithoutComment(ctx Context) Context {
+	return context.WithValue(ctx, commentKey{}, (*commentTags)(nil))
+}
+
+// annotate appends the comment for ctx to query.
+func annotate(ctx Context, d Dialect, query string) string {
+	commenterMu.RLock()
+	c := commenter
+	commenterMu.RUnlock()
+	if c == nil {
+		return query
+	}
+
+	tags := map[string]string{}
+	if v, ok := ctx.Value(commentKey{}).(*commentTags); ok {
+		if v == nil {
+			return query
+		}
+		for k, v := range v.tags {
+			tags[k] = v
+		}
+	}
+	if c.App != "" {
+		tags["app"] = c.App
+	}
+	if c.Caller {
+		if caller := callerName(); caller != "" {
+			tags["caller"] = caller
+		}
+	}
+	if len(tags) == 0 {
+		return query
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, commentEscape(k)+"='"+commentEscape(tags[k])+"'")
+	}
+
+	sep := " "
+	lastLine := query[strings.LastIndexByte(query, '\n')+1:]
+	if strings.Contains(lastLine, "--") || lexOptionsOf(d).hashComment && strings.Contains(lastLine, "#") {
+		// the comment could be swallowed by a line comment
+		sep = "\n"
+	}
+	return query + sep + "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// commentEscape percent-encodes s except for unreserved characters of URI.
+func commentEscape(s string) string {
+	const hex = "0123456789ABCDEF"
+	b := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
+
+const packagePrefix = "github.com/acidlemon/seacle."
+
+// callerName returns the first function outside seacle in the call stack as
+// "pkg.Func". Tests of seacle itself count as outside.
+func callerName() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) || strings.HasSuffix(frame.File, "_test.go") {
+			name := frame.Function
+			if i := strings.LastIndexByte(name, '/'); i >= 0 {
+				name = name[i+1:]
+			}
+			return name
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package seacle
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCommenter(t *testing.T) {
+	SetCommenter(&Commenter{App: "seacle test", Caller: true})
+	defer SetCommenter(nil)
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin: %s", err)
+	}
+	defer tx.Rollback()
+	rec := &recorder{Executable: tx}
+
+	ctx := WithCommentTag(context.Background(), "route", "/users/*/ OR 1=1 --")
+	people := []*Person{}
+	err = Select(ctx, rec, &people, `WHERE id = ?`, 1)
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+	// comment must not be swallowed by a line comment
+	err = Select(ctx, rec, &people, "WHERE id = ? -- the first\n", 1)
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+	people = []*Person{}
+	err = Select(ctx, rec, &people, `WHERE id = ? -- the first`, 1)
+	if err != nil || len(people) != 1 {
+		t.Fatalf("failed to select: %v, %v", people, err)
+	}
+	err = Delete(WithoutComment(ctx), rec, &Person{ID: 1})
+	if err != nil {
+		t.Fatalf("failed to delete: %s", err)
+	}
+	SetCommenter(nil)
+	err = Delete(ctx, rec, &Person{ID: 2})
+	if err != nil {
+		t.Fatalf("failed to delete: %s", err)
+	}
+
+	comment := `/*app='seacle%20test',caller='seacle.TestCommenter',route='%2Fusers%2F%2A%2F%20OR%201%3D1%20--'*/`
+	expected := []string{
+		`SELECT person.id, person.name, person.created_at FROM person WHERE id = ? ` + comment,
+		"SELECT person.id, person.name, person.created_at FROM person WHERE id = ? -- the first\n " + comment,
+		"SELECT person.id, person.name, person.created_at FROM person WHERE id = ? -- the first\n" + comment,
+		`DELETE FROM person WHERE id = ?`,
+		`DELETE FROM person WHERE id = ?`,
+	}
+	if len(rec.queries) != len(expected) {
+		t.Fatalf("unexpected queries: %v", rec.queries)
+	}
+	for i, q := range rec.queries {
+		if q != expected[i] {
+			t.Errorf("unexpected query: %s", q)
+		}
+	}
+}
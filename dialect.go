@@ -0,0 +1,364 @@
+package seacle
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Dialect describes how SQL should be rendered for a particular database.
+// Queries given to seacle are always written with "?" placeholders, and the
+// dialect decides how each of them looks in the final statement.
+type Dialect interface {
+	// Name returns a human readable name of the dialect.
+	Name() string
+	// Placeholder returns the n-th (1-origin) bind placeholder.
+	Placeholder(n int) string
+	// QuoteIdent quotes a single identifier such as a table or column name.
+	QuoteIdent(name string) string
+	// SupportsReturning reports whether Insert should fetch the auto
+	// increment column by "INSERT ... RETURNING" instead of LastInsertId.
+	SupportsReturning() bool
+	// MaxParams returns how many bind parameters a single statement may have.
+	MaxParams() int
+	// OnConflictUpdate renders the clause appended to an INSERT statement so
+	// that a row conflicting on keys gets updates overwritten by the new
+	// values. keys and updates are already quoted.
+	OnConflictUpdate(keys, updates []string) (string, error)
+	// InsertIgnore renders the clause appended to an INSERT statement so that
+	// a row conflicting on a unique key is skipped. Other errors such as NOT
+	// NULL violations must not be ignored. keys are the quoted primary keys.
+	InsertIgnore(keys []string) (string, error)
+}
+
+type dialect struct {
+	name        string
+	placeholder func(n int) string
+	quote       func(name string) string
+	returning   bool
+	maxParams   int
+	// insertIDs derives the IDs of a multi-row INSERT from LastInsertId.
+	insertIDs func(lastInsertID int64, rows int) []int64
+	upsert    func(keys, updates []string) string
+	// upsertID lets LastInsertId of an upsert report the updated row.
+	upsertID func(col string) string
+	ignore   func(keys []string) string
+	lex      lexOptions
+	explain  string // prefix to show the query plan, empty if unsupported
+}
+
+func (d *dialect) Name() string {
+	return d.name
+}
+
+func (d *dialect) Placeholder(n int) string {
+	return d.placeholder(n)
+}
+
+func (d *dialect) QuoteIdent(name string) string {
+	if d.quote == nil {
+		return name
+	}
+	return d.quote(name)
+}
+
+func (d *dialect) SupportsReturning() bool {
+	return d.returning
+}
+
+func (d *dialect) MaxParams() int {
+	return d.maxParams
+}
+
+func (d *dialect) OnConflictUpdate(keys, updates []string) (string, error) {
+	if d.upsert == nil {
+		return "", fmt.Errorf("%s does not support upsert", d.name)
+	}
+	if len(keys) == 0 {
+		return "", fmt.Errorf("no conflict target")
+	}
+	return d.upsert(keys, updates), nil
+}
+
+func (d *dialect) InsertIgnore(keys []string) (string, error) {
+	if d.ignore == nil {
+		return "", fmt.Errorf("%s does not support insert-or-ignore", d.name)
+	}
+	if len(keys) == 0 {
+		return "", fmt.Errorf("no conflict target")
+	}
+	return d.ignore(keys), nil
+}
+
+func (d *dialect) bulkInsertIDs(lastInsertID int64, rows int) []int64 {
+	if d.insertIDs == nil {
+		return nil
+	}
+	return d.insertIDs(lastInsertID, rows)
+}
+
+func (d *dialect) upsertInsertID(col string) string {
+	if d.upsertID == nil {
+		return ""
+	}
+	return d.upsertID(col)
+}
+
+// upsertIDer is implemented by dialects whose upsert may update a row
+// conflicting on a key other than the auto increment column. It returns the
+// assignment appended to the update clause so that LastInsertId is the ID of
+// the updated row instead of zero.
+type upsertIDer interface {
+	upsertInsertID(col string) string
+}
+
+// bulkInsertIDer is implemented by dialects which know what LastInsertId of
+// a multi-row INSERT means. Dialects must not implement it unless the IDs
+// are guaranteed to be consecutive and in the order of VALUES.
+type bulkInsertIDer interface {
+	bulkInsertIDs(lastInsertID int64, rows int) []int64
+}
+
+// idsFromLast is for SQLite, which returns the ID of the last row. Rows of a
+// statement get consecutive IDs in order, since the database is locked while
+// writing.
+func idsFromLast(lastInsertID int64, rows int) []int64 {
+	ids := make([]int64, 0, rows)
+	for i := rows - 1; i >= 0; i-- {
+		ids = append(ids, lastInsertID-int64(i))
+	}
+	return ids
+}
+
+// duplicateKeyUpdate renders MySQL's "ON DUPLICATE KEY UPDATE".
+func duplicateKeyUpdate(keys, updates []string) string {
+	if len(updates) == 0 {
+		// keep the row as it is
+		return fmt.Sprintf("ON DUPLICATE KEY UPDATE %s = %s", keys[0], keys[0])
+	}
+	kv := make([]string, 0, len(updates))
+	for _, v := range updates {
+		kv = append(kv, fmt.Sprintf("%s = VALUES(%s)", v, v))
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(kv, ", ")
+}
+
+// onConflictDoUpdate renders "ON CONFLICT ... DO UPDATE" of PostgreSQL and
+// SQLite 3.24+.
+func onConflictDoUpdate(keys, updates []string) string {
+	if len(updates) == 0 {
+		return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(keys, ", "))
+	}
+	kv := make([]string, 0, len(updates))
+	for _, v := range updates {
+		kv = append(kv, fmt.Sprintf("%s = excluded.%s", v, v))
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keys, ", "), strings.Join(kv, ", "))
+}
+
+// lastInsertIDOf is for MySQL, where LAST_INSERT_ID is not set when ON
+// DUPLICATE KEY UPDATE updates a row.
+func lastInsertIDOf(col string) string {
+	return fmt.Sprintf("%s = LAST_INSERT_ID(%s)", col, col)
+}
+
+// keepDuplicateKey makes MySQL skip a duplicated row by a no-op update.
+// "INSERT IGNORE" is not used since it turns other errors into warnings.
+func keepDuplicateKey(keys []string) string {
+	return duplicateKeyUpdate(keys, nil)
+}
+
+// onConflictDoNothing skips a row conflicting on any unique key.
+// "INSERT OR IGNORE" of SQLite is not used since it ignores NOT NULL and
+// CHECK constraints as well.
+func onConflictDoNothing(keys []string) string {
+	return "ON CONFLICT DO NOTHING"
+}
+
+func questionPlaceholder(n int) string {
+	return "?"
+}
+
+func prefixedPlaceholder(prefix string) func(n int) string {
+	return func(n int) string {
+		return prefix + strconv.Itoa(n)
+	}
+}
+
+func enclosedQuote(open, close string) func(name string) string {
+	return func(name string) string {
+		return open + strings.Replace(name, close, close+close, -1) + close
+	}
+}
+
+var (
+	// Generic renders queries as they are written and never quotes
+	// identifiers. This is the default.
+	Generic Dialect = &dialect{
+		name:        "generic",
+		placeholder: questionPlaceholder,
+		maxParams:   999,
+		explain:     "EXPLAIN",
+	}
+	// MySQL is the dialect for MySQL and MariaDB.
+	MySQL Dialect = &dialect{
+		name:        "mysql",
+		placeholder: questionPlaceholder,
+		quote:       enclosedQuote("`", "`"),
+		maxParams:   65535,
+		upsert:      duplicateKeyUpdate,
+		upsertID:    lastInsertIDOf,
+		ignore:      keepDuplicateKey,
+		lex:         lexOptions{backslashEscape: true, hashComment: true, atVariable: true},
+		explain:     "EXPLAIN",
+	}
+	// SQLite is the dialect for SQLite.
+	SQLite Dialect = &dialect{
+		name:        "sqlite",
+		placeholder: questionPlaceholder,
+		quote:       enclosedQuote(`"`, `"`),
+		maxParams:   999,
+		insertIDs:   idsFromLast,
+		upsert:      onConflictDoUpdate,
+		ignore:      onConflictDoNothing,
+		explain:     "EXPLAIN QUERY PLAN",
+	}
+	// PostgreSQL is the dialect for PostgreSQL ($1, $2, ...).
+	PostgreSQL Dialect = &dialect{
+		name:        "postgres",
+		placeholder: prefixedPlaceholder("$"),
+		quote:       enclosedQuote(`"`, `"`),
+		returning:   true,
+		maxParams:   65535,
+		upsert:      onConflictDoUpdate,
+		ignore:      onConflictDoNothing,
+		explain:     "EXPLAIN",
+		lex:         lexOptions{jsonbOperators: true},
+	}
+	// SQLServer is the dialect for Microsoft SQL Server (@p1, @p2, ...).
+	SQLServer Dialect = &dialect{
+		name:        "sqlserver",
+		placeholder: prefixedPlaceholder("@p"),
+		quote:       enclosedQuote("[", "]"),
+		maxParams:   2100,
+	}
+	// Oracle is the dialect for Oracle Database (:1, :2, ...).
+	Oracle Dialect = &dialect{
+		name:        "oracle",
+		placeholder: prefixedPlaceholder(":"),
+		quote:       enclosedQuote(`"`, `"`),
+		maxParams:   65535,
+	}
+)
+
+var plainIdentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// quoteName quotes name which may be qualified like "person.id". Anything
+// that does not look like a plain identifier (expressions, already quoted
+// names, "*") is returned as it is.
+func quoteName(d Dialect, name string) string {
+	parts := strings.Split(name, ".")
+	for i, v := range parts {
+		if v == "*" && i > 0 && i == len(parts)-1 {
+			continue
+		}
+		if !plainIdentRe.MatchString(v) {
+			return name
+		}
+	}
+	for i, v := range parts {
+		if v != "*" {
+			parts[i] = d.QuoteIdent(v)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteRef quotes a column described by ColumnRef.
+func quoteRef(d Dialect, ref ColumnRef) string {
+	if ref.Table == "" {
+		return d.QuoteIdent(ref.Column)
+	}
+	return quoteName(d, ref.Table) + "." + d.QuoteIdent(ref.Column)
+}
+
+func quoteNames(d Dialect, names []string) []string {
+	quoted := make([]string, 0, len(names))
+	for _, v := range names {
+		quoted = append(quoted, quoteName(d, v))
+	}
+	return quoted
+}
+
+var (
+	defaultDialectMu sync.RWMutex
+	defaultDialect   = Generic
+)
+
+// SetDefaultDialect changes the dialect used for handles that do not carry
+// their own one (see WithDialect).
+func SetDefaultDialect(d Dialect) {
+	if d == nil {
+		d = Generic
+	}
+	defaultDialectMu.Lock()
+	defaultDialect = d
+	defaultDialectMu.Unlock()
+}
+
+// DefaultDialect returns the dialect set by SetDefaultDialect.
+func DefaultDialect() Dialect {
+	defaultDialectMu.RLock()
+	defer defaultDialectMu.RUnlock()
+	return defaultDialect
+}
+
+// Dialecter is implemented by handles which know their own dialect.
+type Dialecter interface {
+	Dialect() Dialect
+}
+
+// handleWrapper is implemented by handles returned by seacle's With* helpers
+// so that settings of inner handles stay visible through outer wrappers.
+type handleWrapper interface {
+	unwrap() Selectable
+}
+
+func dialectOf(s Selectable) Dialect {
+	for s != nil {
+		if d, ok := s.(Dialecter); ok {
+			if dialect := d.Dialect(); dialect != nil {
+				return dialect
+			}
+		}
+		w, ok := s.(handleWrapper)
+		if !ok {
+			break
+		}
+		s = w.unwrap()
+	}
+	return DefaultDialect()
+}
+
+type dialectHandle struct {
+	Executable
+	dialect Dialect
+}
+
+// WithDialect returns an Executable which makes seacle render queries in d.
+func WithDialect(e Executable, d Dialect) Executable {
+	return &dialectHandle{
+		Executable: e,
+		dialect:    d,
+	}
+}
+
+func (h *dialectHandle) Dialect() Dialect {
+	return h.dialect
+}
+
+func (h *dialectHandle) unwrap() Selectable {
+	return h.Executable
+}
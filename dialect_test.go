@@ -0,0 +1,170 @@
+package seacle
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestExpandPlaceholderDialect(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+	}{
+		{Generic, "WHERE id IN (?,?,?) AND name = ? AND age > ?"},
+		{MySQL, "WHERE id IN (?,?,?) AND name = ? AND age > ?"},
+		{SQLite, "WHERE id IN (?,?,?) AND name = ? AND age > ?"},
+		{PostgreSQL, "WHERE id IN ($1,$2,$3) AND name = $4 AND age > $5"},
+		{SQLServer, "WHERE id IN (@p1,@p2,@p3) AND name = @p4 AND age > @p5"},
+		{Oracle, "WHERE id IN (:1,:2,:3) AND name = :4 AND age > :5"},
+	}
+
+	for _, tt := range tests {
+		query, args, err := expandPlaceholder(tt.dialect, "WHERE id IN (?) AND name = ? AND age > ?", []int{1, 2, 3}, "Lamimi", 20)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.dialect.Name(), err)
+		}
+		if query != tt.query {
+			t.Errorf("%s: unexpected query: %s", tt.dialect.Name(), query)
+		}
+		expected := []interface{}{1, 2, 3, "Lamimi", 20}
+		if !reflect.DeepEqual(args, expected) {
+			t.Errorf("%s: unexpected args: %v", tt.dialect.Name(), args)
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := rebind(PostgreSQL, "UPDATE person SET name = ?, created_at = ? WHERE id = ?")
+	if query != "UPDATE person SET name = $1, created_at = $2 WHERE id = $3" {
+		t.Errorf("unexpected query: %s", query)
+	}
+}
+
+func TestDialectOf(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+
+	if d := dialectOf(conn); d != Generic {
+		t.Errorf("unexpected default dialect: %s", d.Name())
+	}
+
+	h := WithDialect(conn, PostgreSQL)
+	if d := dialectOf(h); d != PostgreSQL {
+		t.Errorf("unexpected dialect: %s", d.Name())
+	}
+
+	SetDefaultDialect(MySQL)
+	defer SetDefaultDialect(nil)
+	if d := dialectOf(conn); d != MySQL {
+		t.Errorf("unexpected default dialect: %s", d.Name())
+	}
+
+	// SQLite handle works with seacle functions
+	people := []*Person{}
+	err = Select(ctx, WithDialect(conn, SQLite), &people, `WHERE name IN (?) AND id > ?`, []string{"Alberto", "Lamimi"}, 1)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if len(people) != 1 {
+		t.Errorf("len(people) != 1")
+	}
+}
+
+func TestQuoteName(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		name     string
+		expected string
+	}{
+		{Generic, "person.id", "person.id"},
+		{MySQL, "person.id", "`person`.`id`"},
+		{MySQL, "order", "`order`"},
+		{SQLite, "person.id", `"person"."id"`},
+		{PostgreSQL, "public.user", `"public"."user"`},
+		{SQLServer, "group", "[group]"},
+		{PostgreSQL, "person.*", `"person".*`},
+		{PostgreSQL, "COUNT(*)", "COUNT(*)"},
+		{PostgreSQL, `"already"`, `"already"`},
+	}
+
+	for _, tt := range tests {
+		if actual := quoteName(tt.dialect, tt.name); actual != tt.expected {
+			t.Errorf("%s: quoteName(%s) = %s, expected %s", tt.dialect.Name(), tt.name, actual, tt.expected)
+		}
+	}
+
+	if actual := quoteRef(MySQL, ColumnRef{Table: "person", Column: "a.b"}); actual != "`person`.`a.b`" {
+		t.Errorf("unexpected quoteRef: %s", actual)
+	}
+	if actual := SQLServer.QuoteIdent("a]b"); actual != "[a]]b]" {
+		t.Errorf("unexpected QuoteIdent: %s", actual)
+	}
+}
+
+func TestQuotedIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS "group" (id INTEGER PRIMARY KEY AUTOINCREMENT, "order" VARCHAR(80))`)
+	if err != nil {
+		t.Fatalf("failed to create table: %s", err)
+	}
+	defer conn.ExecContext(ctx, `DROP TABLE "group"`)
+
+	h := WithDialect(conn, SQLite)
+	id, err := Insert(ctx, h, &Group{Order: "first"})
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+
+	g := &Group{}
+	err = SelectRow(ctx, h, g, `WHERE id = ?`, id)
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+	if g.Order != "first" {
+		t.Errorf("unexpected order: %s", g.Order)
+	}
+
+	g.Order = "second"
+	err = Update(ctx, h, g)
+	if err != nil {
+		t.Errorf("failed to update: %s", err)
+	}
+
+	groups := []*Group{}
+	err = Select(ctx, h, &groups, `WHERE "order" = ?`, "second")
+	if err != nil {
+		t.Errorf("failed to select: %s", err)
+	}
+	if len(groups) != 1 {
+		t.Errorf("len(groups) != 1")
+	}
+
+	err = Delete(ctx, h, g)
+	if err != nil {
+		t.Errorf("failed to delete: %s", err)
+	}
+}
+
+func TestBulkInsertIDs(t *testing.T) {
+	ids := SQLite.(bulkInsertIDer).bulkInsertIDs(10, 3)
+	if !reflect.DeepEqual(ids, []int64{8, 9, 10}) {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+	// not guaranteed to be consecutive
+	for _, d := range []Dialect{MySQL, PostgreSQL} {
+		if ids := d.(bulkInsertIDer).bulkInsertIDs(10, 3); ids != nil {
+			t.Errorf("%s: unexpected ids: %v", d.Name(), ids)
+		}
+	}
+}
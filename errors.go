@@ -0,0 +1,150 @@
+package seacle
+
+import (
+	"errors"
+	"reflect"
+)
+
+// driverError holds codes extracted from an error returned by a driver.
+// seacle does not import drivers, so the codes are read by duck typing.
+type driverError struct {
+	sqlState       string // SQLSTATE (PostgreSQL, MySQL)
+	mysqlNumber    int    // error number of MySQL
+	sqliteCode     int    // primary result code of SQLite
+	sqliteExtended int    // extended result code of SQLite
+}
+
+const (
+	pkgMySQL  = "github.com/go-sql-driver/mysql"
+	pkgSQLite = "github.com/mattn/go-sqlite3"
+	pkgPq     = "github.com/lib/pq"
+)
+
+// inspectError finds a driver error in the chain of err.
+func inspectError(err error) (driverError, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		// pgx and lib/pq
+		if s, ok := err.(interface{ SQLState() string }); ok {
+			return driverError{sqlState: s.SQLState()}, true
+		}
+
+		vp := reflect.ValueOf(err)
+		if vp.Kind() == reflect.Ptr {
+			vp = vp.Elem()
+		}
+		if vp.Kind() != reflect.Struct {
+			continue
+		}
+		switch vp.Type().PkgPath() {
+		case pkgMySQL:
+			// *mysql.MySQLError{Number uint16, SQLState [5]byte}
+			de := driverError{}
+			if f := vp.FieldByName("Number"); f.IsValid() && f.Kind() >= reflect.Uint && f.Kind() <= reflect.Uint64 {
+				de.mysqlNumber = int(f.Uint())
+			} else {
+				continue
+			}
+			if f := vp.FieldByName("SQLState"); f.IsValid() && f.Kind() == reflect.Array && f.Len() == 5 {
+				state := make([]byte, 0, 5)
+				for i := 0; i < 5; i++ {
+					state = append(state, byte(f.Index(i).Uint()))
+				}
+				de.sqlState = string(state)
+			}
+			return de, true
+		case pkgSQLite:
+			// sqlite3.Error{Code ErrNo, ExtendedCode ErrNoExtended}
+			code := vp.FieldByName("Code")
+			extended := vp.FieldByName("ExtendedCode")
+			if code.IsValid() && code.Kind() == reflect.Int && extended.IsValid() && extended.Kind() == reflect.Int {
+				return driverError{
+					sqliteCode:     int(code.Int()),
+					sqliteExtended: int(extended.Int()),
+				}, true
+			}
+		case pkgPq:
+			// old *pq.Error without SQLState()
+			if f := vp.FieldByName("Code"); f.IsValid() && f.Kind() == reflect.String {
+				return driverError{sqlState: f.String()}, true
+			}
+		}
+	}
+	return driverError{}, false
+}
+
+func matchError(err error, sqlStates []string, mysqlNumbers []int, sqliteCodes []int, sqliteExtended []int) bool {
+	de, ok := inspectError(err)
+	if !ok {
+		return false
+	}
+	for _, v := range sqlStates {
+		if de.sqlState == v {
+			return true
+		}
+	}
+	for _, v := range mysqlNumbers {
+		if de.mysqlNumber == v {
+			return true
+		}
+	}
+	for _, v := range sqliteCodes {
+		if de.sqliteCode == v {
+			return true
+		}
+	}
+	for _, v := range sqliteExtended {
+		if de.sqliteExtended == v {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDuplicateKey reports whether err is a violation of a primary key or an
+// unique constraint.
+func IsDuplicateKey(err error) bool {
+	return matchError(err,
+		[]string{"23505"}, // unique_violation
+		[]int{1062, 1586}, // ER_DUP_ENTRY, ER_DUP_ENTRY_WITH_KEY_NAME
+		nil,
+		[]int{1555, 2067}) // SQLITE_CONSTRAINT_PRIMARYKEY, SQLITE_CONSTRAINT_UNIQUE
+}
+
+// IsForeignKeyViolation reports whether err is a violation of a foreign key
+// constraint.
+func IsForeignKeyViolation(err error) bool {
+	return matchError(err,
+		[]string{"23503"},             // foreign_key_violation
+		[]int{1216, 1217, 1451, 1452}, // ER_NO_REFERENCED_ROW(_2), ER_ROW_IS_REFERENCED(_2)
+		nil,
+		[]int{787}) // SQLITE_CONSTRAINT_FOREIGNKEY
+}
+
+// IsDeadlock reports whether err is caused by a deadlock.
+func IsDeadlock(err error) bool {
+	return matchError(err,
+		[]string{"40P01"}, // deadlock_detected
+		[]int{1213},       // ER_LOCK_DEADLOCK
+		nil,
+		nil)
+}
+
+// IsLockTimeout reports whether err is caused by giving up waiting a lock.
+func IsLockTimeout(err error) bool {
+	return matchError(err,
+		[]string{"55P03"}, // lock_not_available
+		[]int{1205, 3572}, // ER_LOCK_WAIT_TIMEOUT, ER_LOCK_NOWAIT
+		[]int{5, 6},       // SQLITE_BUSY, SQLITE_LOCKED
+		nil)
+}
+
+// IsSerializationFailure reports whether err is a serialization failure,
+// which means the transaction should be retried. MySQL reports deadlocks as
+// SQLSTATE 40001, so they are also serialization failures.
+func IsSerializationFailure(err error) bool {
+	return matchError(err,
+		[]string{"40001"}, // serialization_failure
+		nil,
+		nil,
+		nil)
+}
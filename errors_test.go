@@ -0,0 +1,110 @@
+package seacle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// pgError mimics *pgconn.PgError of pgx.
+type pgError struct {
+	Code string
+}
+
+func (e *pgError) Error() string    { return "pg error " + e.Code }
+func (e *pgError) SQLState() string { return e.Code }
+
+func TestErrorClassification(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+
+	for _, q := range []string{
+		`PRAGMA foreign_keys = ON`,
+		`CREATE TABLE IF NOT EXISTS team (id INTEGER PRIMARY KEY, name VARCHAR(80) UNIQUE)`,
+		`CREATE TABLE IF NOT EXISTS player (id INTEGER PRIMARY KEY AUTOINCREMENT, team_id INTEGER REFERENCES team (id))`,
+	} {
+		if _, err := conn.ExecContext(ctx, q); err != nil {
+			t.Fatalf("failed to exec %s: %s", q, err)
+		}
+	}
+	defer conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`)
+	defer conn.ExecContext(ctx, `DROP TABLE team`)
+	defer conn.ExecContext(ctx, `DROP TABLE player`)
+
+	_, err = Insert(ctx, conn, &Team{ID: 1, Name: "red"})
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+	_, err = Insert(ctx, conn, &Team{ID: 2, Name: "blue"})
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+	_, err = Insert(ctx, conn, &Player{TeamID: 1})
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+
+	// primary key
+	_, err = Insert(ctx, conn, &Team{ID: 1, Name: "green"})
+	if !IsDuplicateKey(err) || IsForeignKeyViolation(err) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// unique key
+	err = Update(ctx, conn, &Team{ID: 2, Name: "red"})
+	if !IsDuplicateKey(err) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// referencing
+	_, err = Insert(ctx, conn, &Player{TeamID: 3})
+	if !IsForeignKeyViolation(err) || IsDuplicateKey(err) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// referenced
+	err = Delete(ctx, conn, &Team{ID: 1})
+	if !IsForeignKeyViolation(err) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var qe *QueryError
+	if !errors.As(err, &qe) || qe.Op != "Delete" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	busy := fmt.Errorf("wrapped: %w", sqlite3.Error{Code: sqlite3.ErrBusy})
+	if !IsLockTimeout(busy) || IsDeadlock(busy) {
+		t.Errorf("unexpected classification: %v", busy)
+	}
+
+	tests := []struct {
+		code  string
+		check func(error) bool
+	}{
+		{"23505", IsDuplicateKey},
+		{"23503", IsForeignKeyViolation},
+		{"40P01", IsDeadlock},
+		{"55P03", IsLockTimeout},
+		{"40001", IsSerializationFailure},
+	}
+	for _, tt := range tests {
+		err := &QueryError{Op: "Insert", Err: &pgError{Code: tt.code}}
+		if !tt.check(err) {
+			t.Errorf("%s is not classified", tt.code)
+		}
+		if IsDuplicateKey(err) != (tt.code == "23505") {
+			t.Errorf("%s is classified as duplicate key", tt.code)
+		}
+	}
+
+	for _, err := range []error{nil, errors.New("Duplicate entry"), sqlite3.Error{Code: sqlite3.ErrConstraint}} {
+		if IsDuplicateKey(err) || IsForeignKeyViolation(err) || IsDeadlock(err) || IsLockTimeout(err) || IsSerializationFailure(err) {
+			t.Errorf("unexpected classification: %v", err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package seacle
+
+import (
+	"database/sql"
+)
+
+// Event has a primary key given by the application.
+type Event struct {
+	ID      string `db:"id,primary"`
+	Payload string `db:"payload"`
+}
+
+func (p *Event) Table() string {
+	return "event"
+}
+
+func (p *Event) Columns() []string {
+	return []string{"event.id", "event.payload"}
+}
+
+func (p *Event) PrimaryKeys() []string {
+	return []string{"id"}
+}
+
+func (p *Event) PrimaryValues() []interface{} {
+	return []interface{}{p.ID}
+}
+
+func (p *Event) ValueColumns() []string {
+	return []string{"payload"}
+}
+
+func (p *Event) Values() []interface{} {
+	return []interface{}{p.Payload}
+}
+
+func (p *Event) AutoIncrementColumn() string {
+	return ""
+}
+
+func (p *Event) Scan(r RowScanner) error {
+	var arg0 string
+	var arg1 string
+
+	err := r.Scan(&arg0, &arg1)
+	if err == sql.ErrNoRows {
+		return err
+	} else if err != nil {
+		// something wrong
+		return err
+	}
+
+	p.ID = arg0
+	p.Payload = arg1
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package seacle
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Findable is a Mappable which knows its primary keys. Types generated by
+// seacle.Generator are Findable.
+type Findable interface {
+	Mappable
+	PrimaryKeys() []string
+}
+
+var findableIf = reflect.TypeOf((*Findable)(nil)).Elem()
+
+// primaryCondition returns "pk1 = ? AND pk2 = ?" for keys.
+func primaryCondition(d Dialect, keys []string) string {
+	kv := make([]string, 0, len(keys))
+	for _, v := range keys {
+		kv = append(kv, fmt.Sprintf("%s = ?", quoteName(d, v)))
+	}
+	return strings.Join(kv, " AND ")
+}
+
+// Find fetches the row whose primary keys are pk into out. pk must be given
+// in the order of PrimaryKeys(). It returns sql.ErrNoRows as it is when no
+// row matches.
+func Find(ctx Context, s Selectable, out Findable, pk ...interface{}) error {
+	keys := out.PrimaryKeys()
+	if len(keys) != len(pk) {
+		return fmt.Errorf("Find: %d primary values are given for %d primary keys", len(pk), len(keys))
+	}
+
+	fragment := "WHERE " + primaryCondition(dialectOf(s), keys)
+	return SelectRow(ctx, s, out, fragment, pk...)
+}
+
+// FindMany fetches the rows whose primary keys are in pks into out, which is
+// a pointer of slice like Select. For a single primary key each element of
+// pks is a value, and for composite keys each element is a slice (or array)
+// of values in the order of PrimaryKeys(), which is looked up by a tuple
+// "IN" condition. Like BulkInsert, pks are split into several statements so
+// that none exceeds MaxParams() of the dialect. The order of rows is not
+// specified.
+func FindMany(ctx Context, s Selectable, out interface{}, pks ...interface{}) error {
+	tp, _, err := sliceOfMappable(out)
+	if err != nil {
+		return fmt.Errorf("FindMany: %s", err)
+	}
+	if !tp.Implements(findableIf) {
+		return fmt.Errorf("FindMany: %s does not have PrimaryKeys()", tp.String())
+	}
+	if len(pks) == 0 {
+		return nil
+	}
+
+	d := dialectOf(s)
+	keys := reflect.Zero(tp).Interface().(Findable).PrimaryKeys()
+	chunkSize := len(pks)
+	if d.MaxParams() > 0 {
+		chunkSize = d.MaxParams() / len(keys)
+		if chunkSize < 1 {
+			return fmt.Errorf("FindMany: too many primary keys for %s: %d", d.Name(), len(keys))
+		}
+	}
+
+	var fragment string
+	var values reflect.Value
+	if len(keys) == 1 {
+		fragment = fmt.Sprintf("WHERE %s IN (?)", quoteName(d, keys[0]))
+		values = reflect.ValueOf(pks)
+	} else {
+		tuples := make([]Tuple, 0, len(pks))
+		for i, v := range pks {
+			vp := reflect.ValueOf(v)
+			if vp.Kind() != reflect.Slice && vp.Kind() != reflect.Array {
+				return fmt.Errorf("FindMany: pks[%d] is not slice of primary values: %T", i, v)
+			}
+			if vp.Len() != len(keys) {
+				return fmt.Errorf("FindMany: pks[%d] has %d values for %d primary keys", i, vp.Len(), len(keys))
+			}
+			tuple := make(Tuple, 0, len(keys))
+			for j := 0; j < vp.Len(); j++ {
+				tuple = append(tuple, vp.Index(j).Interface())
+			}
+			tuples = append(tuples, tuple)
+		}
+		fragment = fmt.Sprintf("WHERE (%s) IN (?)", strings.Join(quoteNames(d, keys), ", "))
+		values = reflect.ValueOf(tuples)
+	}
+
+	// Select appends rows to out, so each chunk is fetched into it in turn
+	for start := 0; start < values.Len(); start += chunkSize {
+		end := start + chunkSize
+		if end > values.Len() {
+			end = values.Len()
+		}
+		err := Select(ctx, s, out, fragment, values.Slice(start, end).Interface())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
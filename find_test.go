@@ -0,0 +1,121 @@
+package seacle
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+
+	person := &Person{}
+	err = Find(ctx, conn, person, 2)
+	if err != nil {
+		t.Errorf("failed to find: %s", err)
+	}
+	if person.Name != "Lamimi" {
+		t.Errorf("unexpected name: %s", person.Name)
+	}
+
+	err = Find(ctx, conn, person, 9999)
+	if err != sql.ErrNoRows {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = Find(ctx, conn, person, 1, "Alberto")
+	if err == nil || err.Error() != "Find: 2 primary values are given for 1 primary keys" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	people := []*Person{}
+	err = FindMany(ctx, conn, &people, 1, 3, 9999)
+	if err != nil {
+		t.Errorf("failed to find: %s", err)
+	}
+	if len(people) != 2 {
+		t.Errorf("len(people) != 2")
+	}
+
+	people = []*Person{}
+	err = FindMany(ctx, conn, &people)
+	if err != nil || len(people) != 0 {
+		t.Errorf("unexpected result: %v, %v", people, err)
+	}
+}
+
+func TestFindCompositeKeys(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS member (id INTEGER, name VARCHAR(80), role VARCHAR(20), PRIMARY KEY (id, name))`)
+	if err != nil {
+		t.Fatalf("failed to create table: %s", err)
+	}
+	defer conn.ExecContext(ctx, `DROP TABLE member`)
+	_, err = conn.ExecContext(ctx, `INSERT INTO member (id, name, role) VALUES
+		(1, "Alberto", "tank"), (1, "Lamimi", "healer"), (2, "Alberto", "dps"), (2, "Lamimi", "tank")`)
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+
+	m := &Member{}
+	err = Find(ctx, WithDialect(conn, SQLite), m, 2, "Lamimi")
+	if err != nil {
+		t.Errorf("failed to find: %s", err)
+	}
+	if m.Role != "tank" {
+		t.Errorf("unexpected role: %s", m.Role)
+	}
+
+	members := []Member{}
+	err = FindMany(ctx, WithDialect(conn, SQLite), &members, []interface{}{1, "Lamimi"}, [2]interface{}{2, "Alberto"}, []interface{}{3, "Alberto"})
+	if err != nil {
+		t.Fatalf("failed to find: %s", err)
+	}
+	roles := []string{}
+	for _, v := range members {
+		roles = append(roles, v.Role)
+	}
+	sort.Strings(roles)
+	if len(roles) != 2 || roles[0] != "dps" || roles[1] != "healer" {
+		t.Errorf("unexpected roles: %v", roles)
+	}
+
+	// 999 params / 2 keys = 499 tuples per statement
+	rec := &recorder{Executable: conn}
+	pks := make([]interface{}, 0, 1200)
+	for i := 0; i < 1200; i++ {
+		pks = append(pks, []interface{}{i, "Lamimi"})
+	}
+	members = []Member{}
+	err = FindMany(ctx, WithDialect(rec, SQLite), &members, pks...)
+	if err != nil {
+		t.Fatalf("failed to find: %s", err)
+	}
+	if len(rec.queries) != 3 {
+		t.Errorf("unexpected number of statements: %d", len(rec.queries))
+	}
+	if len(members) != 2 {
+		t.Errorf("unexpected number of members: %d", len(members))
+	}
+
+	err = FindMany(ctx, conn, &members, 1)
+	if err == nil || err.Error() != "FindMany: pks[0] is not slice of primary values: int" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = FindMany(ctx, conn, &members, []interface{}{1})
+	if err == nil || err.Error() != "FindMany: pks[0] has 1 values for 2 primary keys" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
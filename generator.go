@@ -17,13 +17,14 @@ type columnInfo struct {
 	Field  string
 	Column string
 	Type   string
+	Secret bool
 }
 
 type Generator struct {
 	Tag string
 }
 
-func (g Generator) analyzeColumn(field reflect.StructField) (string, bool, bool) {
+func (g Generator) analyzeColumn(field reflect.StructField) (string, bool, bool, bool) {
 	// at first, find column from tag
 	structTag := field.Tag
 	tag, _ := structTag.Lookup(g.Tag)
@@ -33,10 +34,12 @@ func (g Generator) analyzeColumn(field reflect.StructField) (string, bool, bool)
 		tag = snaker.CamelToSnake(field.Name)
 	}
 
-	// check flags ( `db:"id,primary"` means primary column, `db:"id,auto_increment" means auto increment column` )
+	// check flags ( `db:"id,primary"` means primary column, `db:"id,auto_increment" means auto increment column`,
+	// `db:"password,secret"` means the value is redacted in errors and logs )
 	ss := strings.Split(tag, ",")
 	isPrimary := false
 	isAutoIncrement := false
+	isSecret := false
 	if len(ss) > 1 {
 		for _, v := range ss[1:] {
 			if v == "primary" {
@@ -45,15 +48,18 @@ func (g Generator) analyzeColumn(field reflect.StructField) (string, bool, bool)
 			if v == "auto_increment" {
 				isAutoIncrement = true
 			}
+			if v == "secret" {
+				isSecret = true
+			}
 		}
 	}
 
 	if ss[0] == "-" {
 		// skip tag
-		return "", false, false
+		return "", false, false, false
 	}
 
-	return ss[0], isPrimary, isAutoIncrement
+	return ss[0], isPrimary, isAutoIncrement, isSecret
 }
 
 func (g Generator) analyzeStruct(tp reflect.Type) (primary []columnInfo, values []columnInfo, autoIncrementCol string) {
@@ -92,7 +98,7 @@ func (g Generator) analyzeField(field reflect.StructField) (primary []columnInfo
 		}
 	}
 
-	column, isPrimary, isAutoIncrement := g.analyzeColumn(field)
+	column, isPrimary, isAutoIncrement, isSecret := g.analyzeColumn(field)
 	//log.Println("column=", column, "isPrimary=", isPrimary, "isAutoIncrement=", isAutoIncrement)
 	if column == "" {
 		return
@@ -101,6 +107,7 @@ func (g Generator) analyzeField(field reflect.StructField) (primary []columnInfo
 		Field:  field.Name,
 		Column: column,
 		Type:   field.Type.String(),
+		Secret: isSecret,
 	}
 
 	if isPrimary {
@@ -137,6 +144,13 @@ func (g Generator) analyzeType(tp reflect.Type, pkg, table string) (map[string]i
 		}
 	}
 
+	secrets := []columnInfo{}
+	for _, v := range append(primary, values...) {
+		if v.Secret {
+			secrets = append(secrets, v)
+		}
+	}
+
 	vars := map[string]interface{}{
 		"Package":       pkg,
 		"Table":         table,
@@ -145,6 +159,7 @@ func (g Generator) analyzeType(tp reflect.Type, pkg, table string) (map[string]i
 		"Values":        values,
 		"AutoIncrement": autoIncrementCol,
 		"AllColumns":    append(primary, values...),
+		"Secrets":       secrets,
 	}
 
 	return vars, nil
@@ -193,6 +208,8 @@ import (
 )
 
 var _ seacle.Mappable = (*{{ .Typename }})(nil)
+var _ seacle.ColumnReferer = (*{{ .Typename }})(nil)
+var _ seacle.SecretColumner = (*{{ .Typename }})(nil)
 
 func (p *{{ .Typename }}) Table() string {
 	return "{{ .Table }}"
@@ -202,6 +219,10 @@ func (p *{{ .Typename }}) Columns() []string {
 	return []string{ {{ range $i, $v := .AllColumns }}"{{ $.Table }}.{{ $v.Column }}", {{ end }} }
 }
 
+func (p *{{ .Typename }}) ColumnRefs() []seacle.ColumnRef {
+	return []seacle.ColumnRef{ {{ range $i, $v := .AllColumns }}{Table: "{{ $.Table }}", Column: "{{ $v.Column }}"}, {{ end }} }
+}
+
 func (p *{{ .Typename }}) PrimaryKeys() []string {
 	return []string{ {{ range $i, $v := .Primary }}"{{ $v.Column }}", {{ end }} }
 }
@@ -218,6 +239,10 @@ func (p *{{ .Typename }}) Values() []interface{} {
 	return []interface{}{ {{ range $i, $v := .Values }}p.{{ $v.Field }}, {{ end }} }
 }
 
+func (p *{{ .Typename }}) SecretColumns() []string {
+	return []string{ {{ range $i, $v := .Secrets }}"{{ $v.Column }}", {{ end }} }
+}
+
 func (p *{{ .Typename }}) AutoIncrementColumn() string {
 	return "{{ .AutoIncrement }}"
 }
@@ -1,7 +1,11 @@
 package seacle
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -10,18 +14,40 @@ func TestGenerator(t *testing.T) {
 		Tag: "db",
 	}
 
-	err := gen.Generate(reflect.TypeOf(TestPerson{}), "seacle", "person", "test/test_person.gen.go")
+	dir, err := ioutil.TempDir("", "seacle-generator")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = gen.Generate(reflect.TypeOf(TestPerson{}), "seacle", "person", filepath.Join(dir, "test_person.gen.go"))
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
 
-	err = gen.Generate(reflect.TypeOf(TestPerson2{}), "seacle", "person", "test/test_person2.gen.go")
+	err = gen.Generate(reflect.TypeOf(TestPerson2{}), "seacle", "person", filepath.Join(dir, "test_person2.gen.go"))
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
 
-	err = gen.Generate(reflect.TypeOf(TestPerson3{}), "seacle", "person", "test/test_person3.gen.go")
+	err = gen.Generate(reflect.TypeOf(TestPerson3{}), "seacle", "person", filepath.Join(dir, "test_person3.gen.go"))
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "test_person2.gen.go"))
+	if err != nil {
+		t.Fatalf("failed to read generated file: %s", err)
+	}
+	if !strings.Contains(string(out), `{Table: "person", Column: "uuid"}`) {
+		t.Errorf("ColumnRefs is not generated:\n%s", out)
+	}
+
+	out, err = ioutil.ReadFile(filepath.Join(dir, "test_person3.gen.go"))
+	if err != nil {
+		t.Fatalf("failed to read generated file: %s", err)
+	}
+	if !strings.Contains(string(out), `return []string{"password"}`) {
+		t.Errorf("SecretColumns is not generated:\n%s", out)
+	}
 }
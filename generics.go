@@ -0,0 +1,85 @@
+package seacle
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// MappablePtr is satisfied by *T when *T is Mappable. It lets the generic
+// functions allocate rows without reflection.
+type MappablePtr[T any] interface {
+	*T
+	Mappable
+}
+
+// SelectT is the type-safe version of Select. It returns the rows as a slice
+// of *T, e.g. SelectT[Person](ctx, s, `WHERE name = ?`, name) returns
+// []*Person.
+func SelectT[T any, PT MappablePtr[T]](ctx Context, s Selectable, fragment string, args ...interface{}) (out []PT, err error) {
+	d := dialectOf(s)
+	target, err := if2select(d, reflect.TypeOf(PT(nil)))
+	if err != nil {
+		return nil, fmt.Errorf("SelectT: Invalid output container: %s", err.Error())
+	}
+
+	q := target.prefix + fragment
+	query, exargs, err := expandPlaceholder(d, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("SelectT: %w", err)
+	}
+	query = annotate(ctx, d, query)
+	ctx, hooks := startQuery(ctx, s, "SelectT", target.table, query, exargs, exargs)
+	defer func() { hooks.finish(int64(len(out)), err) }()
+	rows, err := s.QueryContext(ctx, query, exargs...)
+	if err != nil {
+		return nil, formatError("SelectT", target.table, "QueryContext returned error", query, exargs, err)
+	}
+	defer rows.Close()
+
+	out = []PT{}
+	for rows.Next() {
+		p := PT(new(T))
+		err := p.Scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, formatError("SelectT", target.table, "Failed to iterate rows", query, exargs, err)
+	}
+
+	return out, nil
+}
+
+// Get is the type-safe version of SelectRow. It returns sql.ErrNoRows as it
+// is when no row matches.
+func Get[T any, PT MappablePtr[T]](ctx Context, s Selectable, fragment string, args ...interface{}) (_ PT, err error) {
+	d := dialectOf(s)
+	target, err := if2select(d, reflect.TypeOf(PT(nil)))
+	if err != nil {
+		return nil, fmt.Errorf("Get: Invalid output container: %s", err.Error())
+	}
+
+	q := target.prefix + fragment
+	query, exargs, err := expandPlaceholder(d, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Get: %w", err)
+	}
+	p := PT(new(T))
+	query = annotate(ctx, d, query)
+	ctx, hooks := startQuery(ctx, s, "Get", target.table, query, exargs, exargs)
+	row := s.QueryRowContext(ctx, query, exargs...)
+	err = p.Scan(row)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			err = formatError("Get", target.table, "QueryRowContext returned error", query, exargs, err)
+		}
+		hooks.finish(0, err)
+		return nil, err
+	}
+	hooks.finish(1, nil)
+
+	return p, nil
+}
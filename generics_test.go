@@ -0,0 +1,58 @@
+package seacle
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestSelectT(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+
+	people, err := SelectT[Person](ctx, conn, `WHERE name IN (?) ORDER BY id DESC`, []string{"Alberto", "Blanhaerz"})
+	if err != nil {
+		t.Errorf("failed to select: %s", err)
+	}
+	if len(people) != 2 {
+		t.Fatalf("len(people) != 2")
+	}
+	if people[0].Name != "Blanhaerz" || people[1].Name != "Alberto" {
+		t.Errorf("unexpected people: %s, %s", people[0].Name, people[1].Name)
+	}
+
+	// fail (invalid query)
+	_, err = SelectT[Person](ctx, conn, `WHERE naname = ?`, "Lamimi")
+	if err == nil || err.Error() != `SelectT: QueryContext returned error: err="no such column: naname", query="SELECT person.id, person.name, person.created_at FROM person WHERE naname = ?", args=["Lamimi"]` {
+		t.Errorf("unexpect error: %v", err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+
+	person, err := Get[Person](ctx, WithDialect(conn, SQLite), `WHERE name = ?`, "Naillebert")
+	if err != nil {
+		t.Fatalf("Naillebert is not found: %s", err)
+	}
+	if person.ID != 3 {
+		t.Errorf("unexpected id: %d", person.ID)
+	}
+
+	person, err = Get[Person](ctx, conn, `WHERE name = ?`, "Emet-Selch")
+	if err != sql.ErrNoRows {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if person != nil {
+		t.Errorf("unexpected person: %v", person)
+	}
+}
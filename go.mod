@@ -1,10 +1,15 @@
 module github.com/acidlemon/seacle
 
-go 1.13
+go 1.18
 
 require (
 	github.com/google/uuid v1.1.1
-	github.com/mattn/go-sqlite3 v2.0.3+incompatible
+	github.com/mattn/go-sqlite3 v1.14.17
 	github.com/serenize/snaker v0.0.0-20171204205717-a683aaf2d516
 	golang.org/x/tools v0.0.0-20200708003708-134513de8882
 )
+
+require (
+	golang.org/x/mod v0.3.0 // indirect
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
+)
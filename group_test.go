@@ -0,0 +1,61 @@
+package seacle
+
+import (
+	"database/sql"
+)
+
+// Group has identifiers which are reserved words of SQL.
+type Group struct {
+	ID    int64  `db:"id,primary,auto_increment"`
+	Order string `db:"order"`
+}
+
+func (p *Group) Table() string {
+	return "group"
+}
+
+func (p *Group) Columns() []string {
+	return []string{"group.id", "group.order"}
+}
+
+func (p *Group) ColumnRefs() []ColumnRef {
+	return []ColumnRef{{Table: "group", Column: "id"}, {Table: "group", Column: "order"}}
+}
+
+func (p *Group) PrimaryKeys() []string {
+	return []string{"id"}
+}
+
+func (p *Group) PrimaryValues() []interface{} {
+	return []interface{}{p.ID}
+}
+
+func (p *Group) ValueColumns() []string {
+	return []string{"order"}
+}
+
+func (p *Group) Values() []interface{} {
+	return []interface{}{p.Order}
+}
+
+func (p *Group) AutoIncrementColumn() string {
+	return "id"
+}
+
+func (p *Group) Scan(r RowScanner) error {
+	var arg0 int64
+	var arg1 string
+
+	err := r.Scan(&arg0, &arg1)
+	if err == sql.ErrNoRows {
+		return err
+	} else if err != nil {
+		// something wrong
+		return err
+	}
+
+	p.ID = arg0
+	p.Order = arg1
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package seacle
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// QueryEvent describes a statement issued by seacle.
+type QueryEvent struct {
+	Op    string        // seacle function like "Select" or "Insert"
+	Table string        // empty for raw queries
+	Query string        // final query given to the driver
+	Args  []interface{} // redacted like QueryError.Args
+
+	// following fields are filled before AfterQuery is called
+	Duration     time.Duration
+	RowsAffected int64 // affected or fetched rows, -1 if unknown
+	Err          error
+
+	handle  Selectable    // where the statement was issued
+	rawArgs []interface{} // Args before redaction
+}
+
+// Hook observes statements issued by seacle. BeforeQuery is called just
+// before a statement is sent to the driver, and the returned Context is used
+// for the statement. AfterQuery is called when the statement (including
+// fetching rows for Select) finished.
+type Hook interface {
+	BeforeQuery(ctx Context, ev *QueryEvent) Context
+	AfterQuery(ctx Context, ev *QueryEvent)
+}
+
+// HookFuncs makes a Hook from functions. Nil functions are skipped.
+type HookFuncs struct {
+	Before func(ctx Context, ev *QueryEvent) Context
+	After  func(ctx Context, ev *QueryEvent)
+}
+
+func (h HookFuncs) BeforeQuery(ctx Context, ev *QueryEvent) Context {
+	if h.Before == nil {
+		return ctx
+	}
+	return h.Before(ctx, ev)
+}
+
+func (h HookFuncs) AfterQuery(ctx Context, ev *QueryEvent) {
+	if h.After != nil {
+		h.After(ctx, ev)
+	}
+}
+
+var (
+	globalHooksMu sync.RWMutex
+	globalHooks   []Hook
+)
+
+// AddHook installs h for every handle.
+func AddHook(h Hook) {
+	globalHooksMu.Lock()
+	globalHooks = append(globalHooks[:len(globalHooks):len(globalHooks)], h)
+	globalHooksMu.Unlock()
+}
+
+// SetHooks replaces the hooks installed for every handle. SetHooks() removes
+// all of them.
+func SetHooks(hooks ...Hook) {
+	globalHooksMu.Lock()
+	globalHooks = append([]Hook(nil), hooks...)
+	globalHooksMu.Unlock()
+}
+
+// Hooker is implemented by handles which have their own hooks.
+type Hooker interface {
+	Hooks() []Hook
+}
+
+type hookHandle struct {
+	Executable
+	hooks []Hook
+}
+
+// WithHooks returns an Executable which calls hooks for statements issued
+// through it, in addition to the global ones.
+func WithHooks(e Executable, hooks ...Hook) Executable {
+	return &hookHandle{
+		Executable: e,
+		hooks:      hooks,
+	}
+}
+
+func (h *hookHandle) Hooks() []Hook {
+	return h.hooks
+}
+
+func (h *hookHandle) unwrap() Selectable {
+	return h.Executable
+}
+
+// hooksOf returns hooks for s in calling order: global ones, then ones of
+// handles from outer to inner.
+func hooksOf(s Selectable) []Hook {
+	globalHooksMu.RLock()
+	hooks := globalHooks
+	globalHooksMu.RUnlock()
+
+	for s != nil {
+		if h, ok := s.(Hooker); ok {
+			hooks = append(hooks[:len(hooks):len(hooks)], h.Hooks()...)
+		}
+		w, ok := s.(handleWrapper)
+		if !ok {
+			break
+		}
+		s = w.unwrap()
+	}
+	return hooks
+}
+
+// queryHooks runs hooks around a statement. nil means no hooks.
+type queryHooks struct {
+	ctx   Context
+	hooks []Hook
+	event QueryEvent
+	start time.Time
+}
+
+// startQuery calls BeforeQuery of the hooks for s, and returns the Context
+// for the statement. redacted is args passed through redactColumns.
+func startQuery(ctx Context, s Selectable, op, table, query string, args, redacted []interface{}) (Context, *queryHooks) {
+	hooks := hooksOf(s)
+	if len(hooks) == 0 {
+		return ctx, nil
+	}
+
+	h := &queryHooks{
+		hooks: hooks,
+		event: QueryEvent{
+			Op:           op,
+			Table:        table,
+			Query:        query,
+			Args:         RedactArgs(query, redacted),
+			RowsAffected: -1,
+			handle:       s,
+			rawArgs:      args,
+		},
+	}
+	for _, v := range hooks {
+		ctx = v.BeforeQuery(ctx, &h.event)
+	}
+	h.ctx = ctx
+	h.start = time.Now()
+	return ctx, h
+}
+
+// finish calls AfterQuery of the hooks in reverse order.
+func (h *queryHooks) finish(rowsAffected int64, err error) {
+	if h == nil {
+		return
+	}
+	h.event.Duration = time.Since(h.start)
+	h.event.RowsAffected = rowsAffected
+	h.event.Err = err
+	for i := len(h.hooks) - 1; i >= 0; i-- {
+		h.hooks[i].AfterQuery(h.ctx, &h.event)
+	}
+}
+
+// rowsAffected returns RowsAffected of result, or -1 if the driver cannot
+// tell.
+func rowsAffected(result sql.Result) int64 {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,177 @@
+package seacle
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type hookKey struct{}
+
+type hookRecorder struct {
+	name   string
+	calls  *[]string
+	events []QueryEvent
+	// ops whose AfterQuery did not get the context from BeforeQuery
+	lost []string
+}
+
+func (h *hookRecorder) BeforeQuery(ctx Context, ev *QueryEvent) Context {
+	*h.calls = append(*h.calls, "before "+h.name)
+	return context.WithValue(ctx, hookKey{}, h.name)
+}
+
+func (h *hookRecorder) AfterQuery(ctx Context, ev *QueryEvent) {
+	*h.calls = append(*h.calls, "after "+h.name)
+	if ctx.Value(hookKey{}) == nil {
+		h.lost = append(h.lost, ev.Op)
+	}
+	h.events = append(h.events, *ev)
+}
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+	calls := []string{}
+	global := &hookRecorder{name: "global", calls: &calls}
+	local := &hookRecorder{name: "local", calls: &calls}
+	SetHooks(global)
+	defer SetHooks()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin: %s", err)
+	}
+	defer tx.Rollback()
+	h := WithHooks(tx, local)
+
+	people := []*Person{}
+	err = Select(ctx, h, &people, `WHERE id IN (?)`, []int64{1, 2})
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+	if strings.Join(calls, ",") != "before global,before local,after local,after global" {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+
+	p := &Person{Name: "Cecilia", CreatedAt: time.Now()}
+	p.ID, err = Insert(ctx, h, p)
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+	p.Name = "Cecily"
+	err = Update(ctx, h, p)
+	if err != nil {
+		t.Fatalf("failed to update: %s", err)
+	}
+	err = SelectRow(ctx, h, &Person{}, `WHERE id = ?`, p.ID)
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+	err = Delete(ctx, h, p)
+	if err != nil {
+		t.Fatalf("failed to delete: %s", err)
+	}
+	rows, err := QueryContext(ctx, h, `SELECT id FROM person WHERE id = ?`, 1)
+	if err != nil {
+		t.Fatalf("failed to query: %s", err)
+	}
+	rows.Close()
+	var name string
+	err = QueryRowContext(ctx, h, `SELECT nme FROM person WHERE id = ?`, 1).Scan(&name)
+	if err == nil {
+		t.Fatalf("error is expected")
+	}
+	row := QueryRow(ctx, h, `SELECT name FROM person WHERE id = ?`, 1)
+	if len(local.events) != 7 {
+		t.Errorf("QueryRow is finished before Scan")
+	}
+	err = row.Scan(&name)
+	if err != nil {
+		t.Fatalf("failed to query: %s", err)
+	}
+	// not hooked
+	_, err = Count(ctx, tx, &Person{}, ``)
+	if err != nil {
+		t.Fatalf("failed to count: %s", err)
+	}
+
+	expected := []struct {
+		op           string
+		query        string
+		rowsAffected int64
+		failed       bool
+	}{
+		{"Select", "SELECT person.id, person.name, person.created_at FROM person WHERE id IN (?,?)", 2, false},
+		{"Insert", "INSERT INTO person (name,created_at) VALUES (?,?)", 1, false},
+		{"Update", "UPDATE person SET name = ?, created_at = ? WHERE id = ?", 1, false},
+		{"SelectRow", "SELECT person.id, person.name, person.created_at FROM person WHERE id = ?", 1, false},
+		{"Delete", "DELETE FROM person WHERE id = ?", 1, false},
+		{"QueryContext", "SELECT id FROM person WHERE id = ?", -1, false},
+		{"QueryRowContext", "SELECT nme FROM person WHERE id = ?", -1, true},
+		{"QueryRow", "SELECT name FROM person WHERE id = ?", 1, false},
+	}
+	if len(local.events) != len(expected) || len(global.events) != len(expected)+1 {
+		t.Fatalf("unexpected number of events: %d, %d", len(local.events), len(global.events))
+	}
+	for i, ev := range local.events {
+		e := expected[i]
+		if ev.Op != e.op || ev.Query != e.query || ev.RowsAffected != e.rowsAffected || (ev.Err != nil) != e.failed {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+		if !strings.HasPrefix(ev.Op, "Query") && ev.Table != "person" {
+			t.Errorf("unexpected table: %+v", ev)
+		}
+		if ev.Duration <= 0 {
+			t.Errorf("duration is not measured: %+v", ev)
+		}
+	}
+	if ev := local.events[4]; len(ev.Args) != 1 || ev.Args[0] != p.ID {
+		t.Errorf("unexpected args: %v", ev.Args)
+	}
+	if ev := global.events[len(global.events)-1]; ev.Op != "Count" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if len(local.lost) != 0 || len(global.lost) != 0 {
+		t.Errorf("context is not passed from BeforeQuery: %v, %v", local.lost, global.lost)
+	}
+}
+
+func TestHooksBulkInsert(t *testing.T) {
+	ctx := context.Background()
+	calls := []string{}
+	rec := &hookRecorder{name: "local", calls: &calls}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin: %s", err)
+	}
+	defer tx.Rollback()
+	h := WithHooks(WithDialect(tx, SQLite), rec)
+
+	in := []Modifiable{
+		&Person{Name: "Cecilia", CreatedAt: time.Now()},
+		&Person{Name: "Hythlodaeus", CreatedAt: time.Now()},
+	}
+	_, _, err = BulkInsert(ctx, h, in)
+	if err != nil {
+		t.Fatalf("failed to bulk insert: %s", err)
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = BulkInsert(canceled, h, in)
+	if err == nil {
+		t.Fatalf("error is expected")
+	}
+
+	if len(rec.events) != 2 {
+		t.Fatalf("unexpected number of events: %d", len(rec.events))
+	}
+	if ev := rec.events[0]; ev.Op != "BulkInsert" || ev.RowsAffected != 2 || ev.Err != nil {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	// the error of the query reaches hooks
+	if ev := rec.events[1]; ev.Err != err {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+}
@@ -0,0 +1,199 @@
+package seacle
+
+import (
+	"strings"
+)
+
+// lexOptions describes dialect specific lexical rules.
+type lexOptions struct {
+	// backslashEscape means backslash escapes a character in string literals.
+	backslashEscape bool
+	// hashComment means "#" starts a comment until the end of line.
+	hashComment bool
+	// atVariable means "@name" is a user variable, not a named parameter.
+	atVariable bool
+	// jsonbOperators means "?|" and "?&" are operators, not placeholders.
+	jsonbOperators bool
+}
+
+func lexOptionsOf(d Dialect) lexOptions {
+	if v, ok := d.(*dialect); ok {
+		return v.lex
+	}
+	return lexOptions{}
+}
+
+// replacePlaceholders replaces each "?" placeholder of q with the result of
+// bind. "?" in string literals, quoted identifiers and comments is left as it
+// is, "??" is unescaped into a literal "?", and PostgreSQL's "?|" and "?&"
+// operators are not placeholders. positional reports whether q also has
+// positional parameters like "$1", which cannot be mixed with "?".
+func replacePlaceholders(d Dialect, q string, bind func() string) (query string, positional bool) {
+	if strings.IndexByte(q, '?') < 0 {
+		return q, false
+	}
+	return scanQuery(d, q, false, func(string) string {
+		return bind()
+	})
+}
+
+// replaceNamedParams replaces each ":name" or "@name" (except for MySQL)
+// parameter of q with the result of bind. A literal "?" is escaped into "??" so that the result can
+// be given to replacePlaceholders.
+func replaceNamedParams(d Dialect, q string, bind func(name string) string) string {
+	query, _ := scanQuery(d, q, true, bind)
+	return query
+}
+
+func scanQuery(d Dialect, q string, named bool, bind func(name string) string) (string, bool) {
+	opts := lexOptionsOf(d)
+	positional := false
+	b := strings.Builder{}
+	b.Grow(len(q))
+	last := 0 // beginning of the part not written yet
+	i := 0
+	for i < len(q) {
+		switch c := q[i]; {
+		case c == '\'':
+			i = skipQuoted(q, i, c, opts.backslashEscape)
+		case c == '"':
+			// a string literal in MySQL, where backslash escapes work
+			i = skipQuoted(q, i, c, opts.backslashEscape)
+		case c == '`':
+			i = skipQuoted(q, i, c, false)
+		case c == '-' && strings.HasPrefix(q[i:], "--"):
+			i = skipLine(q, i)
+		case c == '#' && opts.hashComment:
+			i = skipLine(q, i)
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			i = skipBlockComment(q, i)
+		case c == '$':
+			if (i == 0 || !isIdentByte(q[i-1])) && i+1 < len(q) && '0' <= q[i+1] && q[i+1] <= '9' {
+				positional = true
+			}
+			i = skipDollarQuoted(q, i)
+		case (c == ':' || c == '@' && !opts.atVariable) && named:
+			if i > 0 && (q[i-1] == c || isIdentByte(q[i-1])) {
+				// "::" cast, "@@" system variable or a part of identifier
+				i++
+				continue
+			}
+			j := i + 1
+			if j >= len(q) || !isIdentStartByte(q[j]) {
+				i++
+				continue
+			}
+			for j < len(q) && isIdentByte(q[j]) && q[j] != '$' {
+				j++
+			}
+			b.WriteString(q[last:i])
+			b.WriteString(bind(q[i+1 : j]))
+			i = j
+			last = i
+		case c == '?' && named:
+			// literal "?" and "??"
+			b.WriteString(q[last:i])
+			b.WriteString("??")
+			i++
+			if i < len(q) && q[i] == '?' {
+				i++
+			}
+			last = i
+		case c == '?':
+			next := byte(0)
+			if i+1 < len(q) {
+				next = q[i+1]
+			}
+			switch {
+			case next == '?':
+				// escaped "?"
+				b.WriteString(q[last : i+1])
+				i += 2
+				last = i
+			case opts.jsonbOperators && (next == '&' || next == '|' && !strings.HasPrefix(q[i+1:], "||")):
+				// jsonb operators
+				i += 2
+			default:
+				b.WriteString(q[last:i])
+				b.WriteString(bind(""))
+				i++
+				last = i
+			}
+		default:
+			i++
+		}
+	}
+	b.WriteString(q[last:])
+
+	return b.String(), positional
+}
+
+// skipQuoted returns the position next to the quoted token beginning at i.
+// A doubled quote character is an escaped quote.
+func skipQuoted(q string, i int, quote byte, backslashEscape bool) int {
+	for i++; i < len(q); i++ {
+		switch q[i] {
+		case '\\':
+			if backslashEscape {
+				i++
+			}
+		case quote:
+			if i+1 < len(q) && q[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(q)
+}
+
+func skipLine(q string, i int) int {
+	n := strings.IndexByte(q[i:], '\n')
+	if n < 0 {
+		return len(q)
+	}
+	return i + n + 1
+}
+
+func skipBlockComment(q string, i int) int {
+	n := strings.Index(q[i+2:], "*/")
+	if n < 0 {
+		return len(q)
+	}
+	return i + 2 + n + 2
+}
+
+func isIdentStartByte(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80
+}
+
+// skipDollarQuoted skips PostgreSQL's dollar quoted string like $tag$...$tag$.
+func skipDollarQuoted(q string, i int) int {
+	if i > 0 && isIdentByte(q[i-1]) {
+		// a part of identifier
+		return i + 1
+	}
+	j := i + 1
+	if j < len(q) && '0' <= q[j] && q[j] <= '9' {
+		// positional parameter like $1
+		return i + 1
+	}
+	for j < len(q) && q[j] != '$' && isIdentByte(q[j]) {
+		j++
+	}
+	if j >= len(q) || q[j] != '$' {
+		return i + 1
+	}
+
+	tag := q[i : j+1]
+	n := strings.Index(q[j+1:], tag)
+	if n < 0 {
+		return len(q)
+	}
+	return j + 1 + n + len(tag)
+}
@@ -0,0 +1,83 @@
+package seacle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReplacePlaceholders(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		query    string
+		expected string
+	}{
+		{PostgreSQL, `WHERE note = 'why?' AND id = ?`, `WHERE note = 'why?' AND id = $1`},
+		{PostgreSQL, `WHERE note = 'it''s ?' AND id = ?`, `WHERE note = 'it''s ?' AND id = $1`},
+		{PostgreSQL, `WHERE "what?" = ? AND id = ?`, `WHERE "what?" = $1 AND id = $2`},
+		{PostgreSQL, "WHERE `a?` = ?", "WHERE `a?` = $1"},
+		{PostgreSQL, "WHERE id = ? -- really?\nAND name = ?", "WHERE id = $1 -- really?\nAND name = $2"},
+		{PostgreSQL, `WHERE /* why? */ id = ?`, `WHERE /* why? */ id = $1`},
+		{PostgreSQL, `WHERE data ?? 'key' AND id = ?`, `WHERE data ? 'key' AND id = $1`},
+		{PostgreSQL, `WHERE data ?| ? AND data ?& ?`, `WHERE data ?| $1 AND data ?& $2`},
+		{PostgreSQL, `WHERE name = ?|| 'x'`, `WHERE name = $1|| 'x'`},
+		{PostgreSQL, `WHERE body = $$why?$$ AND id = ?`, `WHERE body = $$why?$$ AND id = $1`},
+		{PostgreSQL, `WHERE body = $tag$why?$tag$ AND id = ?`, `WHERE body = $tag$why?$tag$ AND id = $1`},
+		{PostgreSQL, `WHERE note = 'unterminated ?`, `WHERE note = 'unterminated ?`},
+		{MySQL, `WHERE note = 'it\'s ?' AND id = ? # why?`, `WHERE note = 'it\'s ?' AND id = ? # why?`},
+		{MySQL, `WHERE a = "it\"s ?" AND b = ?`, `WHERE a = "it\"s ?" AND b = ?`},
+		{SQLite, `WHERE note = 'C:\' AND id = ?`, `WHERE note = 'C:\' AND id = ?`},
+		// jsonb operators are only of PostgreSQL
+		{MySQL, `WHERE flags = ?|?`, `WHERE flags = ?|?`},
+		{SQLite, `WHERE flags & ?&?`, `WHERE flags & ?&?`},
+	}
+
+	for _, tt := range tests {
+		n := 0
+		actual, _ := replacePlaceholders(tt.dialect, tt.query, func() string {
+			n++
+			return tt.dialect.Placeholder(n)
+		})
+		if actual != tt.expected {
+			t.Errorf("%s: unexpected query for %s: %s", tt.dialect.Name(), tt.query, actual)
+		}
+	}
+
+	for _, tt := range []struct {
+		dialect Dialect
+		query   string
+	}{
+		{MySQL, `WHERE flags = ?|?`},
+		{SQLite, `WHERE flags & ?&?`},
+		{MySQL, `WHERE a = "it\"s" AND b = ?|?`},
+	} {
+		_, args, err := expandPlaceholder(tt.dialect, tt.query, 1, 2)
+		if err != nil || len(args) != 2 {
+			t.Errorf("%s: unexpected result for %s: %v, %v", tt.dialect.Name(), tt.query, args, err)
+		}
+	}
+
+	// "?" cannot be numbered after "$1"
+	_, _, err := expandPlaceholder(PostgreSQL, `WHERE id = $1 OR id = ?`, 1)
+	if !errors.Is(err, ErrMixedPlaceholders) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	query, args, err := expandPlaceholder(PostgreSQL, `WHERE note = 'why?' AND id IN (?) AND name = ?`, []int{1, 2}, "Lamimi")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if query != `WHERE note = 'why?' AND id IN ($1,$2) AND name = $3` {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 2, "Lamimi"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func BenchmarkExpandPlaceholder(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		expandPlaceholder(MySQL, `SELECT person.id, person.name FROM person WHERE name = 'why?' AND id IN (?) AND name = ?`, []int{1, 2, 3}, "Lamimi")
+	}
+}
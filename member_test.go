@@ -0,0 +1,60 @@
+package seacle
+
+import (
+	"database/sql"
+)
+
+// Member has a composite primary key.
+type Member struct {
+	ID   int64  `db:"id,primary"`
+	Name string `db:"name,primary"`
+	Role string `db:"role"`
+}
+
+func (p *Member) Table() string {
+	return "member"
+}
+
+func (p *Member) Columns() []string {
+	return []string{"member.id", "member.name", "member.role"}
+}
+
+func (p *Member) PrimaryKeys() []string {
+	return []string{"id", "name"}
+}
+
+func (p *Member) PrimaryValues() []interface{} {
+	return []interface{}{p.ID, p.Name}
+}
+
+func (p *Member) ValueColumns() []string {
+	return []string{"role"}
+}
+
+func (p *Member) Values() []interface{} {
+	return []interface{}{p.Role}
+}
+
+func (p *Member) AutoIncrementColumn() string {
+	return ""
+}
+
+func (p *Member) Scan(r RowScanner) error {
+	var arg0 int64
+	var arg1 string
+	var arg2 string
+
+	err := r.Scan(&arg0, &arg1, &arg2)
+	if err == sql.ErrNoRows {
+		return err
+	} else if err != nil {
+		// something wrong
+		return err
+	}
+
+	p.ID = arg0
+	p.Name = arg1
+	p.Role = arg2
+
+	return nil
+}
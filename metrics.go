@@ -0,0 +1,198 @@
+package seacle
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are upper bounds (in seconds) of latency histograms used
+// when NewMetrics is called without buckets.
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics is a Hook which counts statements and records their latencies in
+// histograms labelled by op and table. It is an in-process registry; use
+// WritePrometheus to serve it in the Prometheus text format, or publish it
+// by expvar.Publish("seacle", m) since it implements expvar.Var.
+//
+//	m := seacle.NewMetrics()
+//	seacle.AddHook(m)
+type Metrics struct {
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[metricsKey]*metricsSeries
+}
+
+type metricsKey struct {
+	op    string
+	table string
+}
+
+type metricsSeries struct {
+	count   uint64
+	errors  uint64
+	sum     float64
+	buckets []uint64 // not cumulative; the last one is for +Inf
+}
+
+// MetricsSample is a snapshot of Metrics for an op and a table.
+type MetricsSample struct {
+	Op      string          `json:"op"`
+	Table   string          `json:"table"`
+	Count   uint64          `json:"count"`
+	Errors  uint64          `json:"errors"` // sql.ErrNoRows, even if wrapped, is not counted
+	Sum     float64         `json:"sum"`    // total latency in seconds
+	Buckets []MetricsBucket `json:"buckets"`
+}
+
+// MetricsBucket is a cumulative bucket of a latency histogram.
+type MetricsBucket struct {
+	UpperBound float64 `json:"le"` // +Inf for the last one
+	Count      uint64  `json:"count"`
+}
+
+// MarshalJSON writes +Inf as a string because JSON has no infinity.
+func (b MetricsBucket) MarshalJSON() ([]byte, error) {
+	le := interface{}(b.UpperBound)
+	if math.IsInf(b.UpperBound, 1) {
+		le = "+Inf"
+	}
+	return json.Marshal(map[string]interface{}{"le": le, "count": b.Count})
+}
+
+// NewMetrics returns an empty Metrics. buckets are upper bounds of the
+// latency histograms in seconds, DefaultBuckets if not given.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{
+		buckets: buckets,
+		series:  map[metricsKey]*metricsSeries{},
+	}
+}
+
+func (m *Metrics) BeforeQuery(ctx Context, ev *QueryEvent) Context {
+	return ctx
+}
+
+func (m *Metrics) AfterQuery(ctx Context, ev *QueryEvent) {
+	seconds := ev.Duration.Seconds()
+	i := sort.SearchFloat64s(m.buckets, seconds) // the first bucket with seconds <= bound
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := metricsKey{op: ev.Op, table: ev.Table}
+	s, ok := m.series[key]
+	if !ok {
+		s = &metricsSeries{buckets: make([]uint64, len(m.buckets)+1)}
+		m.series[key] = s
+	}
+	s.count++
+	if ev.Err != nil && !errors.Is(ev.Err, sql.ErrNoRows) {
+		s.errors++
+	}
+	s.sum += seconds
+	s.buckets[i]++
+}
+
+// Snapshot returns current values sorted by op and table.
+func (m *Metrics) Snapshot() []MetricsSample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	samples := make([]MetricsSample, 0, len(m.series))
+	for key, s := range m.series {
+		sample := MetricsSample{
+			Op:      key.op,
+			Table:   key.table,
+			Count:   s.count,
+			Errors:  s.errors,
+			Sum:     s.sum,
+			Buckets: make([]MetricsBucket, 0, len(s.buckets)),
+		}
+		var cumulative uint64
+		for i, v := range s.buckets {
+			cumulative += v
+			le := math.Inf(1)
+			if i < len(m.buckets) {
+				le = m.buckets[i]
+			}
+			sample.Buckets = append(sample.Buckets, MetricsBucket{UpperBound: le, Count: cumulative})
+		}
+		samples = append(samples, sample)
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].Op != samples[j].Op {
+			return samples[i].Op < samples[j].Op
+		}
+		return samples[i].Table < samples[j].Table
+	})
+	return samples
+}
+
+// String returns the snapshot as JSON, so that Metrics can be published by
+// expvar.
+func (m *Metrics) String() string {
+	b, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		return "null"
+	}
+	return string(b)
+}
+
+// WritePrometheus writes the snapshot in the Prometheus text exposition
+// format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	samples := m.Snapshot()
+	buf := &bytes.Buffer{}
+
+	buf.WriteString("# HELP seacle_queries_total Number of statements issued by seacle.\n")
+	buf.WriteString("# TYPE seacle_queries_total counter\n")
+	for _, s := range samples {
+		fmt.Fprintf(buf, "seacle_queries_total{%s} %d\n", metricsLabels(s, ""), s.Count)
+	}
+	buf.WriteString("# HELP seacle_query_errors_total Number of statements failed.\n")
+	buf.WriteString("# TYPE seacle_query_errors_total counter\n")
+	for _, s := range samples {
+		fmt.Fprintf(buf, "seacle_query_errors_total{%s} %d\n", metricsLabels(s, ""), s.Errors)
+	}
+	buf.WriteString("# HELP seacle_query_duration_seconds Latency of statements issued by seacle.\n")
+	buf.WriteString("# TYPE seacle_query_duration_seconds histogram\n")
+	for _, s := range samples {
+		for _, b := range s.Buckets {
+			le := "+Inf"
+			if !math.IsInf(b.UpperBound, 1) {
+				le = strconv.FormatFloat(b.UpperBound, 'g', -1, 64)
+			}
+			fmt.Fprintf(buf, "seacle_query_duration_seconds_bucket{%s} %d\n", metricsLabels(s, le), b.Count)
+		}
+		fmt.Fprintf(buf, "seacle_query_duration_seconds_sum{%s} %s\n", metricsLabels(s, ""), strconv.FormatFloat(s.Sum, 'g', -1, 64))
+		fmt.Fprintf(buf, "seacle_query_duration_seconds_count{%s} %d\n", metricsLabels(s, ""), s.Count)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func metricsLabels(s MetricsSample, le string) string {
+	labels := fmt.Sprintf(`op="%s",table="%s"`, labelEscaper.Replace(s.Op), labelEscaper.Replace(s.Table))
+	if le != "" {
+		labels += fmt.Sprintf(`,le="%s"`, le)
+	}
+	return labels
+}
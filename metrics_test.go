@@ -0,0 +1,90 @@
+package seacle
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"strings"
+	"testing"
+)
+
+var _ expvar.Var = (*Metrics)(nil)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	m := NewMetrics(0.5, 0.1)
+	h := WithHooks(db, m)
+
+	people := []*Person{}
+	for i := 0; i < 2; i++ {
+		err := Select(ctx, h, &people, `WHERE id = ?`, 1)
+		if err != nil {
+			t.Fatalf("failed to select: %s", err)
+		}
+	}
+	err := SelectRow(ctx, h, &Person{}, `WHERE id = ?`, 9999)
+	if err != sql.ErrNoRows {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = Delete(ctx, h, &Account{ID: 1})
+	if err == nil {
+		t.Fatalf("error is expected")
+	}
+	// sql.ErrNoRows wrapped in QueryError
+	_, err = Count(ctx, h, &Person{}, `GROUP BY id HAVING id < 0`)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	samples := m.Snapshot()
+	if len(samples) != 4 {
+		t.Fatalf("unexpected samples: %+v", samples)
+	}
+	expected := []struct {
+		op     string
+		table  string
+		count  uint64
+		errors uint64
+	}{
+		{"Count", "person", 1, 0},
+		{"Delete", "no_account", 1, 1},
+		{"Select", "person", 2, 0},
+		{"SelectRow", "person", 1, 0},
+	}
+	for i, s := range samples {
+		e := expected[i]
+		if s.Op != e.op || s.Table != e.table || s.Count != e.count || s.Errors != e.errors {
+			t.Errorf("unexpected sample: %+v", s)
+		}
+		if len(s.Buckets) != 3 || s.Buckets[0].UpperBound != 0.1 || s.Buckets[2].Count != s.Count {
+			t.Errorf("unexpected buckets: %+v", s.Buckets)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	err = m.WritePrometheus(buf)
+	if err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+	for _, line := range []string{
+		"# TYPE seacle_queries_total counter\n",
+		`seacle_queries_total{op="Select",table="person"} 2` + "\n",
+		`seacle_query_errors_total{op="Delete",table="no_account"} 1` + "\n",
+		`seacle_query_duration_seconds_bucket{op="Select",table="person",le="+Inf"} 2` + "\n",
+		`seacle_query_duration_seconds_count{op="SelectRow",table="person"} 1` + "\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("%q is not found in:\n%s", line, buf.String())
+		}
+	}
+
+	var raw []map[string]interface{}
+	err = json.Unmarshal([]byte(m.String()), &raw)
+	if err != nil || len(raw) != 4 || raw[2]["op"] != "Select" || raw[2]["count"] != float64(2) ||
+		!strings.Contains(m.String(), `{"count":2,"le":"+Inf"}`) {
+		t.Errorf("unexpected json: %s", m.String())
+	}
+}
@@ -0,0 +1,117 @@
+package seacle
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// NamedTag is the struct tag which Named reads to find parameter names, the
+// same as Generator.Tag in usual.
+var NamedTag = "db"
+
+type namedArg struct {
+	value interface{}
+}
+
+// Named binds ":name" or "@name" parameters in the query from v, which is a
+// map with string keys or a struct (or pointer of struct). For MySQL only
+// ":name" is a parameter because "@name" is a user variable. Fields of struct
+// are named in the same manner as Generator with NamedTag. Named must be the
+// only argument, and "?" in the query is a literal then. Slice values are
+// expanded as positional arguments.
+func Named(v interface{}) interface{} {
+	return namedArg{value: v}
+}
+
+// bindNamed converts named parameters of q into "?" and returns arguments
+// for them in order.
+func bindNamed(d Dialect, q string, v interface{}) (string, []interface{}, error) {
+	values, err := namedValues(v)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := []interface{}{}
+	var missing []string
+	query := replaceNamedParams(d, q, func(name string) string {
+		v, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		args = append(args, v)
+		return "?"
+	})
+	if len(missing) != 0 {
+		return "", nil, fmt.Errorf(`named parameter %s is not given: query="%s"`, missing[0], q)
+	}
+
+	return query, args, nil
+}
+
+func namedValues(v interface{}) (map[string]interface{}, error) {
+	vp := reflect.ValueOf(v)
+	for vp.Kind() == reflect.Ptr {
+		if vp.IsNil() {
+			return nil, fmt.Errorf("Named: nil is given")
+		}
+		vp = vp.Elem()
+	}
+
+	values := map[string]interface{}{}
+	switch vp.Kind() {
+	case reflect.Map:
+		if vp.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("Named: key of map is not string: %s", vp.Type().String())
+		}
+		iter := vp.MapRange()
+		for iter.Next() {
+			values[iter.Key().String()] = iter.Value().Interface()
+		}
+	case reflect.Struct:
+		structValues(Generator{Tag: NamedTag}, vp, values)
+	default:
+		return nil, fmt.Errorf("Named: neither map nor struct: %T", v)
+	}
+	return values, nil
+}
+
+// structValues collects fields of vp into values named like
+// Generator.analyzeField does, though only embedded structs are flattened
+// so that values like time.Time are kept as they are.
+func structValues(g Generator, vp reflect.Value, values map[string]interface{}) {
+	tp := vp.Type()
+	for i := 0; i < tp.NumField(); i++ {
+		f := tp.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// unexported
+			continue
+		}
+		tag, ok := f.Tag.Lookup(g.Tag)
+		if tag == "-" {
+			continue
+		}
+		fv := vp.Field(i)
+		if !ok {
+			inner := fv
+			if inner.Kind() == reflect.Ptr {
+				if inner.IsNil() {
+					continue
+				}
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct && f.Anonymous {
+				// recursive!
+				structValues(g, inner, values)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		column, _, _, _ := g.analyzeColumn(f)
+		if column != "" {
+			values[column] = fv.Interface()
+		}
+	}
+}
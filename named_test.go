@@ -0,0 +1,91 @@
+package seacle
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type namedCond struct {
+	Name    string   `db:"name"`
+	IDs     []int64  `db:"ids"`
+	Ignored string   `db:"-"`
+	MinID   int64    // min_id
+	inner   struct{} // unexported
+	namedEmbedded
+}
+
+type namedEmbedded struct {
+	Since time.Time `db:"since"`
+}
+
+func TestBindNamed(t *testing.T) {
+	since, _ := time.Parse("2006-01-02", "2018-04-01")
+	tests := []struct {
+		dialect  Dialect
+		query    string
+		arg      interface{}
+		expected string
+		exargs   []interface{}
+	}{
+		{
+			Generic,
+			`WHERE name = :name AND id IN (:ids) OR name = @name`,
+			map[string]interface{}{"name": "Lamimi", "ids": []int64{1, 2}},
+			`WHERE name = ? AND id IN (?,?) OR name = ?`,
+			[]interface{}{"Lamimi", int64(1), int64(2), "Lamimi"},
+		},
+		{
+			PostgreSQL,
+			`WHERE id IN (:ids) AND id::text <> :name AND created_at > :since AND id > :min_id AND note = 'a :b' AND data ? 'key'`,
+			&namedCond{Name: "Lamimi", IDs: []int64{1, 2}, MinID: 1, namedEmbedded: namedEmbedded{Since: since}},
+			`WHERE id IN ($1,$2) AND id::text <> $3 AND created_at > $4 AND id > $5 AND note = 'a :b' AND data ? 'key'`,
+			[]interface{}{int64(1), int64(2), "Lamimi", since, int64(1)},
+		},
+		{
+			MySQL,
+			`SELECT @@version, @rownum := :n`,
+			map[string]int{"n": 3},
+			`SELECT @@version, @rownum := ?`,
+			[]interface{}{3},
+		},
+	}
+
+	for _, tt := range tests {
+		query, exargs, err := expandPlaceholder(tt.dialect, tt.query, Named(tt.arg))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.dialect.Name(), err)
+		}
+		if query != tt.expected {
+			t.Errorf("%s: unexpected query: %s", tt.dialect.Name(), query)
+		}
+		if !reflect.DeepEqual(exargs, tt.exargs) {
+			t.Errorf("%s: unexpected args: %v", tt.dialect.Name(), exargs)
+		}
+	}
+
+	_, _, err := expandPlaceholder(Generic, `WHERE name = :name AND id = :id`, Named(map[string]interface{}{"name": "Lamimi"}))
+	if err == nil || err.Error() != `named parameter id is not given: query="WHERE name = :name AND id = :id"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, _, err = expandPlaceholder(Generic, `WHERE name = :name`, Named("Lamimi"))
+	if err == nil || err.Error() != `Named: neither map nor struct: string` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSelectNamed(t *testing.T) {
+	ctx := context.Background()
+	people := []*Person{}
+	err := Select(ctx, db, &people, `WHERE name IN (:names) AND id < :max_id ORDER BY id`, Named(map[string]interface{}{
+		"names":  []string{"Alberto", "Lamimi", "Naillebert"},
+		"max_id": 3,
+	}))
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+	if len(people) != 2 || people[0].Name != "Alberto" || people[1].Name != "Lamimi" {
+		t.Errorf("unexpected people: %v", people)
+	}
+}
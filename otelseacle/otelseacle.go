@@ -0,0 +1,69 @@
+// Package otelseacle adapts OpenTelemetry to seacle.Tracer.
+//
+//	seacle.AddHook(seacle.TracingHook(otelseacle.NewTracer(otel.GetTracerProvider())))
+//
+// It is a module separate from seacle, so that seacle keeps supporting Go
+// 1.18 and does not depend on OpenTelemetry, which requires Go 1.25. To work
+// on both in a checkout, use a workspace instead of a replace directive:
+//
+//	go work init . ./otelseacle
+package otelseacle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acidlemon/seacle"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer obtained from providers.
+const InstrumentationName = "github.com/acidlemon/seacle"
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a seacle.Tracer which starts client spans by tp.
+func NewTracer(tp trace.TracerProvider) seacle.Tracer {
+	return &tracer{tracer: tp.Tracer(InstrumentationName)}
+}
+
+func (t *tracer) Start(ctx context.Context, name string) (context.Context, seacle.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, &otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SetAttribute(key string, value interface{}) {
+	var kv attribute.KeyValue
+	switch v := value.(type) {
+	case string:
+		kv = attribute.String(key, v)
+	case int:
+		kv = attribute.Int(key, v)
+	case int64:
+		kv = attribute.Int64(key, v)
+	case bool:
+		kv = attribute.Bool(key, v)
+	case float64:
+		kv = attribute.Float64(key, v)
+	default:
+		kv = attribute.String(key, fmt.Sprint(v))
+	}
+	s.span.SetAttributes(kv)
+}
+
+func (s *otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+}
@@ -0,0 +1,51 @@
+package otelseacle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/acidlemon/seacle"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tr := NewTracer(tp)
+
+	ctx, span := tr.Start(context.Background(), "Select person")
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		t.Errorf("span is not in context")
+	}
+	span.SetAttribute(seacle.AttrStatement, "SELECT person.id FROM person WHERE id = ?")
+	span.SetAttribute(seacle.AttrOperation, "Select")
+	span.SetAttribute("db.rows", int64(3))
+	span.RecordError(errors.New("no such column"))
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("unexpected spans: %v", spans)
+	}
+	s := spans[0]
+	if s.Name != "Select person" || s.SpanKind != trace.SpanKindClient || s.Status.Code != codes.Error {
+		t.Errorf("unexpected span: %+v", s)
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs[seacle.AttrStatement].AsString() != "SELECT person.id FROM person WHERE id = ?" ||
+		attrs[seacle.AttrOperation].AsString() != "Select" ||
+		attrs["db.rows"].AsInt64() != 3 {
+		t.Errorf("unexpected attributes: %v", s.Attributes)
+	}
+	if len(s.Events) != 1 || s.Events[0].Name != "exception" {
+		t.Errorf("error is not recorded: %v", s.Events)
+	}
+}
@@ -0,0 +1,96 @@
+package seacle
+
+import (
+	"sync"
+)
+
+// Redacted is printed instead of a sensitive argument.
+const Redacted = "[REDACTED]"
+
+// SecretColumner is optionally implemented by Modifiable to tell columns whose
+// values must not appear in errors and logs. seacle.Generator emits it for
+// columns tagged like `db:"password,secret"`.
+type SecretColumner interface {
+	SecretColumns() []string
+}
+
+// RedactionPolicy reports whether args[i] of query should be redacted. It is
+// consulted for every argument printed by seacle, so that arguments of raw
+// queries without column metadata (e.g. QueryContext) can be redacted too.
+type RedactionPolicy func(query string, i int, arg interface{}) bool
+
+// RedactAll is a RedactionPolicy which redacts every argument.
+func RedactAll(query string, i int, arg interface{}) bool {
+	return true
+}
+
+var (
+	redactionPolicyMu sync.RWMutex
+	redactionPolicy   RedactionPolicy
+)
+
+// SetRedactionPolicy sets the global RedactionPolicy. nil means that only
+// secret columns are redacted.
+func SetRedactionPolicy(p RedactionPolicy) {
+	redactionPolicyMu.Lock()
+	redactionPolicy = p
+	redactionPolicyMu.Unlock()
+}
+
+// RedactArgs returns a copy of args of query where arguments hit by the
+// global RedactionPolicy are replaced with Redacted. It is for loggers
+// printing queries by themselves.
+func RedactArgs(query string, args []interface{}) []interface{} {
+	redactionPolicyMu.RLock()
+	policy := redactionPolicy
+	redactionPolicyMu.RUnlock()
+
+	if policy == nil {
+		return args
+	}
+	redacted := make([]interface{}, len(args))
+	for i, v := range args {
+		if v != Redacted && policy(query, i, v) {
+			v = Redacted
+		}
+		redacted[i] = v
+	}
+	return redacted
+}
+
+// redactColumns returns a copy of args where arguments for secret columns of
+// in are replaced with Redacted. args are values of columns, repeated for
+// multi-row statements.
+func redactColumns(in interface{}, columns []string, args []interface{}) []interface{} {
+	secreter, ok := in.(SecretColumner)
+	if !ok || len(columns) == 0 {
+		return args
+	}
+	secrets := secreter.SecretColumns()
+	if len(secrets) == 0 {
+		return args
+	}
+
+	isSecret := make([]bool, len(columns))
+	found := false
+	for i, c := range columns {
+		for _, s := range secrets {
+			if c == s {
+				isSecret[i] = true
+				found = true
+			}
+		}
+	}
+	if !found {
+		return args
+	}
+
+	redacted := make([]interface{}, len(args))
+	for i, v := range args {
+		if isSecret[i%len(columns)] {
+			v = Redacted
+		}
+		redacted[i] = v
+	}
+	return redacted
+}
@@ -0,0 +1,62 @@
+package seacle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	ctx := context.Background()
+	a := &Account{ID: 1, Name: "Lamimi", Password: "hunter2"}
+
+	_, err := Insert(ctx, db, a)
+	if err == nil || !strings.HasSuffix(err.Error(), `args=["Lamimi", "[REDACTED]"]`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = Update(ctx, db, a)
+	if err == nil || !strings.HasSuffix(err.Error(), `args=["Lamimi", "[REDACTED]", "1"]`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, _, err = BulkInsert(ctx, db, []Modifiable{a, &Account{Name: "Alberto", Password: "swordfish"}})
+	if err == nil || !strings.HasSuffix(err.Error(), `args=["Lamimi", "[REDACTED]", "Alberto", "[REDACTED]"]`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var qe *QueryError
+	if !errors.As(err, &qe) || qe.Args[1] != Redacted {
+		t.Errorf("unexpected args: %v", err)
+	}
+	if a.Password != "hunter2" {
+		t.Errorf("password is modified: %s", a.Password)
+	}
+
+	// raw queries are redacted only by the policy
+	_, err = QueryContext(ctx, db, `SELECT * FROM no_account WHERE password = ?`, "hunter2")
+	if err == nil || !strings.HasSuffix(err.Error(), `args=["hunter2"]`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	SetRedactionPolicy(func(query string, i int, arg interface{}) bool {
+		return strings.Contains(query, "password")
+	})
+	defer SetRedactionPolicy(nil)
+
+	_, err = QueryContext(ctx, db, `SELECT * FROM no_account WHERE password = ?`, "hunter2")
+	if err == nil || !strings.HasSuffix(err.Error(), `args=["[REDACTED]"]`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var name string
+	err = QueryRow(ctx, db, `SELECT name FROM no_account WHERE password = ?`, "hunter2").Scan(&name)
+	if err == nil || !strings.HasSuffix(err.Error(), `args=["[REDACTED]"]`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = QueryRow(ctx, db, `SELECT name FROM person WHERE id = ?`, 9999).Scan(&name)
+	if err != sql.ErrNoRows {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if args := RedactArgs(`SELECT 1 WHERE id = ?`, []interface{}{1}); args[0] != 1 {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
@@ -3,44 +3,138 @@ package seacle
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
-	"regexp"
 	"strings"
+	"sync"
 )
 
 type Context = context.Context
 
 var mappableIf = reflect.TypeOf((*Mappable)(nil)).Elem()
 
-func expandPlaceholder(q string, args ...interface{}) (string, []interface{}) {
-	if len(args) == 0 {
-		return q, args
+// Tuple is a group of values bound as "(?,?)". A slice of Tuple (or of
+// []interface{}) expands into "(?,?),(?,?)" for conditions like
+// "WHERE (id, name) IN (?)".
+type Tuple []interface{}
+
+var (
+	// ErrArgumentCount is returned when the number of placeholders and
+	// arguments do not match.
+	ErrArgumentCount = errors.New("seacle: number of placeholders and arguments mismatch")
+	// ErrEmptySlice is returned when an empty slice is given as an argument,
+	// which would make invalid SQL like "IN ()".
+	ErrEmptySlice = errors.New("seacle: empty slice is given for placeholder")
+	// ErrMixedPlaceholders is returned when "?" placeholders are mixed with
+	// positional parameters like "$1", which would be numbered twice.
+	ErrMixedPlaceholders = errors.New(`seacle: "?" placeholders are mixed with positional parameters`)
+)
+
+// expandPlaceholder expands slice arguments into comma separated placeholders
+// (for "IN (?)") and renders each placeholder for the dialect d. Slices which
+// are bound as a single value (see isExpandable) are not expanded. When the
+// only argument is made by Named, named parameters are bound at first.
+func expandPlaceholder(d Dialect, q string, args ...interface{}) (string, []interface{}, error) {
+	if len(args) == 1 {
+		if named, ok := args[0].(namedArg); ok {
+			var err error
+			q, args, err = bindNamed(d, q, named.value)
+			if err != nil {
+				return "", nil, err
+			}
+		}
 	}
 
-	re := regexp.MustCompile("\\?")
 	exargs := []interface{}{}
-	count := 0
-	query := re.ReplaceAllStringFunc(q, func(match string) string {
-		if count >= len(args) {
-			return match // do nothing
+	bind := func(v interface{}) string {
+		exargs = append(exargs, v)
+		return d.Placeholder(len(exargs))
+	}
+	bindTuple := func(t []interface{}) string {
+		placeholders := make([]string, 0, len(t))
+		for _, v := range t {
+			placeholders = append(placeholders, bind(v))
+		}
+		return "(" + strings.Join(placeholders, ",") + ")"
+	}
+
+	var argErr error
+	setErr := func(err error) {
+		if argErr == nil {
+			argErr = err
+		}
+	}
+	bindSlice := func(i int, vp reflect.Value) string {
+		if vp.Len() == 0 {
+			setErr(fmt.Errorf(`%w: args[%d]: query="%s"`, ErrEmptySlice, i, q))
+		}
+		placeholders := make([]string, 0, vp.Len())
+		for j := 0; j < vp.Len(); j++ {
+			switch elem := vp.Index(j).Interface().(type) {
+			case Tuple:
+				placeholders = append(placeholders, bindTuple(elem))
+			case []interface{}:
+				placeholders = append(placeholders, bindTuple(elem))
+			default:
+				placeholders = append(placeholders, bind(elem))
+			}
 		}
-		tp := reflect.TypeOf(args[count])
-		val := args[count]
+		return strings.Join(placeholders, ",")
+	}
+
+	count := 0
+	query, positional := replacePlaceholders(d, q, func() string {
 		count++
-		if tp.Kind() == reflect.Slice {
-			vp := reflect.ValueOf(val)
-			for i := 0; i < vp.Len(); i++ {
-				exargs = append(exargs, vp.Index(i).Interface())
+		if count > len(args) {
+			return "?" // checked later
+		}
+		i := count - 1
+		switch val := args[i].(type) {
+		case Tuple:
+			if len(val) == 0 {
+				setErr(fmt.Errorf(`%w: args[%d]: query="%s"`, ErrEmptySlice, i, q))
 			}
-			return strings.TrimSuffix(strings.Repeat("?,", vp.Len()), ",")
-		} else {
-			exargs = append(exargs, val)
-			return match
+			return bindTuple(val)
+		case literalArg:
+			return bind(val.value)
+		case inArg:
+			vp := reflect.ValueOf(val.value)
+			if vp.Kind() != reflect.Slice && vp.Kind() != reflect.Array {
+				setErr(fmt.Errorf(`args[%d] given to In is not slice: %T: query="%s"`, i, val.value, q))
+				return bind(val.value)
+			}
+			return bindSlice(i, vp)
+		default:
+			if isExpandable(val) {
+				return bindSlice(i, reflect.ValueOf(val))
+			}
+			return bind(val)
 		}
 	})
 
-	return query, exargs
+	if positional && count > 0 {
+		return "", nil, fmt.Errorf(`%w: query="%s"`, ErrMixedPlaceholders, q)
+	}
+	if count != len(args) {
+		return "", nil, fmt.Errorf(`%w: %d placeholders for %d arguments: query="%s"`, ErrArgumentCount, count, len(args), q)
+	}
+	if argErr != nil {
+		return "", nil, argErr
+	}
+
+	return query, exargs, nil
+}
+
+// rebind renders every placeholder in q for the dialect d without touching
+// arguments.
+func rebind(d Dialect, q string) string {
+	count := 0
+	query, _ := replacePlaceholders(d, q, func() string {
+		count++
+		return d.Placeholder(count)
+	})
+	return query
 }
 
 type Selectable interface {
@@ -49,57 +143,162 @@ type Selectable interface {
 }
 
 func QueryContext(ctx Context, s Selectable, query string, args ...interface{}) (*sql.Rows, error) {
-	query, exargs := expandPlaceholder(query, args...)
-	return s.QueryContext(ctx, query, exargs...)
+	query, exargs, err := expandPlaceholder(dialectOf(s), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("QueryContext: %w", err)
+	}
+	ctx, hooks := startQuery(ctx, s, "QueryContext", "", query, exargs, exargs)
+	rows, err := s.QueryContext(ctx, query, exargs...)
+	if err != nil {
+		err = formatError("QueryContext", "", "QueryContext returned error", query, exargs, err)
+		hooks.finish(-1, err)
+		return nil, err
+	}
+	// rows are fetched by the caller
+	hooks.finish(-1, nil)
+	return rows, nil
 }
+
+// QueryRowContext expands placeholders of query and issues it on s like
+// QueryContext. It returns *sql.Row as database/sql does, thus an error of
+// the expansion cannot be returned; the query is then issued with a canceled
+// Context so that it never reaches the database, and the row reports
+// context.Canceled while hooks are told the error. Use QueryRow to get such
+// errors and QueryError.
 func QueryRowContext(ctx Context, s Selectable, query string, args ...interface{}) *sql.Row {
-	query, exargs := expandPlaceholder(query, args...)
-	return s.QueryRowContext(ctx, query, exargs...)
+	exquery, exargs, err := expandPlaceholder(dialectOf(s), query, args...)
+	if err != nil {
+		ctx, hooks := startQuery(ctx, s, "QueryRowContext", "", query, nil, nil)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		row := s.QueryRowContext(canceled, query)
+		hooks.finish(-1, fmt.Errorf("QueryRowContext: %w", err))
+		return row
+	}
+	ctx, hooks := startQuery(ctx, s, "QueryRowContext", "", exquery, exargs, exargs)
+	row := s.QueryRowContext(ctx, exquery, exargs...)
+	if err := row.Err(); err != nil {
+		hooks.finish(-1, formatError("QueryRowContext", "", "QueryRowContext returned error", exquery, exargs, err))
+	} else {
+		hooks.finish(-1, nil)
+	}
+	return row
 }
 
-func Select(ctx Context, s Selectable, out interface{}, fragment string, args ...interface{}) error {
-	// check about "out"
-	var tp reflect.Type
-	isVal := false
-	{
-		checkTp := reflect.TypeOf(out)
-		typeName := checkTp.String()
-		if checkTp.Kind() != reflect.Ptr {
-			return fmt.Errorf("Select: out is not pointer: %s", typeName)
-		}
+// Row is the result of QueryRow. It works like *sql.Row, and also carries
+// the error which happened before the query was issued.
+type Row struct {
+	row   *sql.Row
+	err   error
+	query string
+	args  []interface{}
+	hooks *queryHooks // finished by Scan
+}
 
-		checkTp = checkTp.Elem()
-		if checkTp.Kind() != reflect.Slice {
-			return fmt.Errorf("Select: out is not pointer of slice: %s", typeName)
+func (r *Row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	err := r.row.Scan(dest...)
+	if err != nil && err != sql.ErrNoRows {
+		err = formatError("QueryRow", "", "QueryRowContext returned error", r.query, r.args, err)
+	}
+	if r.hooks != nil {
+		var fetched int64
+		if err == nil {
+			fetched = 1
 		}
+		r.hooks.finish(fetched, err)
+		r.hooks = nil
+	}
+	return err
+}
 
-		checkTp = checkTp.Elem()
-		if !checkTp.Implements(mappableIf) {
-			ptrTp := reflect.PtrTo(checkTp)
-			if ptrTp.Implements(mappableIf) {
-				tp = ptrTp
-				isVal = true
-			} else {
-				return fmt.Errorf("Select: out is not pointer of slice of Mappable: %s", typeName)
-			}
-		} else {
-			tp = checkTp
+func (r *Row) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	if err := r.row.Err(); err != nil {
+		return formatError("QueryRow", "", "QueryRowContext returned error", r.query, r.args, err)
+	}
+	return nil
+}
+
+// QueryRow is QueryRowContext which returns *Row, so that errors of the
+// placeholder expansion are returned by Scan and Err, and errors of the query
+// are QueryError. Hooks are finished by Scan unless the query failed, thus
+// Scan must be called.
+func QueryRow(ctx Context, s Selectable, query string, args ...interface{}) *Row {
+	query, exargs, err := expandPlaceholder(dialectOf(s), query, args...)
+	if err != nil {
+		return &Row{err: fmt.Errorf("QueryRow: %w", err)}
+	}
+	ctx, hooks := startQuery(ctx, s, "QueryRow", "", query, exargs, exargs)
+	r := &Row{row: s.QueryRowContext(ctx, query, exargs...), query: query, args: exargs, hooks: hooks}
+	if err := r.Err(); err != nil {
+		hooks.finish(-1, err)
+		r.hooks = nil
+	}
+	return r
+}
+
+// sliceOfMappable checks out is a pointer of slice of Mappable (or of values
+// whose pointer is Mappable), and returns the Mappable type. isVal is true
+// for the latter.
+func sliceOfMappable(out interface{}) (tp reflect.Type, isVal bool, err error) {
+	checkTp := reflect.TypeOf(out)
+	if checkTp == nil {
+		return nil, false, fmt.Errorf("out is nil")
+	}
+	typeName := checkTp.String()
+	if checkTp.Kind() != reflect.Ptr {
+		return nil, false, fmt.Errorf("out is not pointer: %s", typeName)
+	}
+
+	checkTp = checkTp.Elem()
+	if checkTp.Kind() != reflect.Slice {
+		return nil, false, fmt.Errorf("out is not pointer of slice: %s", typeName)
+	}
+
+	checkTp = checkTp.Elem()
+	if !checkTp.Implements(mappableIf) {
+		ptrTp := reflect.PtrTo(checkTp)
+		if ptrTp.Implements(mappableIf) {
+			return ptrTp, true, nil
 		}
+		return nil, false, fmt.Errorf("out is not pointer of slice of Mappable: %s", typeName)
+	}
+	return checkTp, false, nil
+}
+
+func Select(ctx Context, s Selectable, out interface{}, fragment string, args ...interface{}) (err error) {
+	// check about "out"
+	tp, isVal, err := sliceOfMappable(out)
+	if err != nil {
+		return fmt.Errorf("Select: %s", err)
 	}
 
-	columns, table, err := if2select(tp)
+	d := dialectOf(s)
+	target, err := if2select(d, tp)
 	if err != nil {
 		return fmt.Errorf("Select: Invalid output container: %s", err.Error())
 	}
 
-	q := fmt.Sprintf("SELECT %s FROM %s %s", strings.Join(columns, ", "), table, fragment)
-	query, exargs := expandPlaceholder(q, args...)
+	q := target.prefix + fragment
+	query, exargs, err := expandPlaceholder(d, q, args...)
+	if err != nil {
+		return fmt.Errorf("Select: %w", err)
+	}
+	query = annotate(ctx, d, query)
+	var fetched int64
+	ctx, hooks := startQuery(ctx, s, "Select", target.table, query, exargs, exargs)
+	defer func() { hooks.finish(fetched, err) }()
 	rows, err := s.QueryContext(ctx, query, exargs...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return err
 		}
-		return formatError("Select: QueryContext returned error", query, exargs, err)
+		return formatError("Select", target.table, "QueryContext returned error", query, exargs, err)
 	}
 	defer rows.Close()
 
@@ -121,24 +320,95 @@ func Select(ctx Context, s Selectable, out interface{}, fragment string, args ..
 		} else {
 			outSliceVp.Set(reflect.Append(outSliceVp, vp))
 		}
+		fetched++
 	}
 	return nil
 }
 
-func SelectRow(ctx Context, s Selectable, out interface{}, fragment string, args ...interface{}) error {
+// ErrStop can be returned from the callback of SelectEach to stop iteration
+// without error.
+var ErrStop = errors.New("seacle: stop iteration")
+
+// SelectEach calls fn for each row one by one instead of accumulating them
+// into a slice. Every row is scanned into a new value of the type of proto,
+// so a typed nil like (*Person)(nil) is enough for proto. When fn returns an
+// error, iteration stops and SelectEach returns the error (nil for ErrStop,
+// even if wrapped). Hooks see only errors of the query, not the ones of fn.
+func SelectEach(ctx Context, s Selectable, proto Mappable, fn func(Mappable) error, fragment string, args ...interface{}) error {
+	tp := reflect.TypeOf(proto)
+	if tp == nil || tp.Kind() != reflect.Ptr {
+		return fmt.Errorf("SelectEach: proto is not pointer of Mappable: %v", tp)
+	}
+
+	d := dialectOf(s)
+	target, err := if2select(d, tp)
+	if err != nil {
+		return fmt.Errorf("SelectEach: Invalid prototype: %s", err.Error())
+	}
+
+	q := target.prefix + fragment
+	query, exargs, err := expandPlaceholder(d, q, args...)
+	if err != nil {
+		return fmt.Errorf("SelectEach: %w", err)
+	}
+	query = annotate(ctx, d, query)
+	var fetched int64
+	var failed error // errors of fn are not the query's
+	ctx, hooks := startQuery(ctx, s, "SelectEach", target.table, query, exargs, exargs)
+	defer func() { hooks.finish(fetched, failed) }()
+	rows, err := s.QueryContext(ctx, query, exargs...)
+	if err != nil {
+		failed = formatError("SelectEach", target.table, "QueryContext returned error", query, exargs, err)
+		return failed
+	}
+	defer rows.Close()
+
+	tp = tp.Elem()
+	for rows.Next() {
+		mappable := reflect.New(tp).Interface().(Mappable)
+		err := mappable.Scan(rows)
+		if err != nil {
+			failed = err
+			return err
+		}
+
+		fetched++
+		err = fn(mappable)
+		if errors.Is(err, ErrStop) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		failed = formatError("SelectEach", target.table, "Failed to iterate rows", query, exargs, err)
+		return failed
+	}
+	return nil
+}
+
+func SelectRow(ctx Context, s Selectable, out interface{}, fragment string, args ...interface{}) (err error) {
 	// check about "out"
 	tp := reflect.TypeOf(out)
 	if !tp.Implements(mappableIf) {
 		return fmt.Errorf("SelectRow: out is not Mappable: %s", tp.String())
 	}
 
-	columns, table, err := if2select(tp)
+	d := dialectOf(s)
+	target, err := if2select(d, tp)
 	if err != nil {
 		return fmt.Errorf("SelectRow: Invalid output container: %s", err.Error())
 	}
 
-	q := fmt.Sprintf("SELECT %s FROM %s %s", strings.Join(columns, ", "), table, fragment)
-	query, exargs := expandPlaceholder(q, args...)
+	q := target.prefix + fragment
+	query, exargs, err := expandPlaceholder(d, q, args...)
+	if err != nil {
+		return fmt.Errorf("SelectRow: %w", err)
+	}
+	query = annotate(ctx, d, query)
+	var fetched int64
+	ctx, hooks := startQuery(ctx, s, "SelectRow", target.table, query, exargs, exargs)
+	defer func() { hooks.finish(fetched, err) }()
 	row := s.QueryRowContext(ctx, query, exargs...)
 	mappable := reflect.ValueOf(out).Interface().(Mappable)
 	err = mappable.Scan(row)
@@ -146,12 +416,79 @@ func SelectRow(ctx Context, s Selectable, out interface{}, fragment string, args
 		if err == sql.ErrNoRows {
 			return err
 		}
-		return formatError("SelectRow: QueryRowContext returned error", q, exargs, err)
+		return formatError("SelectRow", target.table, "QueryRowContext returned error", query, exargs, err)
 	}
+	fetched = 1
 
 	return nil
 }
 
+// Count returns the number of rows of the table of m which match fragment.
+// m is used only for its type, so a typed nil like (*Person)(nil) is enough.
+func Count(ctx Context, s Selectable, m Mappable, fragment string, args ...interface{}) (int64, error) {
+	tp := reflect.TypeOf(m)
+	if tp == nil || tp.Kind() != reflect.Ptr {
+		return 0, fmt.Errorf("Count: m is not pointer of Mappable: %v", tp)
+	}
+
+	d := dialectOf(s)
+	target, err := if2select(d, tp)
+	if err != nil {
+		return 0, fmt.Errorf("Count: Invalid container: %s", err.Error())
+	}
+
+	q := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", target.from, fragment)
+	query, exargs, err := expandPlaceholder(d, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("Count: %w", err)
+	}
+	query = annotate(ctx, d, query)
+	var count int64
+	ctx, hooks := startQuery(ctx, s, "Count", target.table, query, exargs, exargs)
+	err = s.QueryRowContext(ctx, query, exargs...).Scan(&count)
+	if err != nil {
+		err = formatError("Count", target.table, "QueryRowContext returned error", query, exargs, err)
+		hooks.finish(-1, err)
+		return 0, err
+	}
+	hooks.finish(1, nil)
+
+	return count, nil
+}
+
+// Exists reports whether the table of m has any row which matches fragment.
+// m is used only for its type like Count.
+func Exists(ctx Context, s Selectable, m Mappable, fragment string, args ...interface{}) (bool, error) {
+	tp := reflect.TypeOf(m)
+	if tp == nil || tp.Kind() != reflect.Ptr {
+		return false, fmt.Errorf("Exists: m is not pointer of Mappable: %v", tp)
+	}
+
+	d := dialectOf(s)
+	target, err := if2select(d, tp)
+	if err != nil {
+		return false, fmt.Errorf("Exists: Invalid container: %s", err.Error())
+	}
+
+	q := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s %s)", target.from, fragment)
+	query, exargs, err := expandPlaceholder(d, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("Exists: %w", err)
+	}
+	query = annotate(ctx, d, query)
+	var exists bool
+	ctx, hooks := startQuery(ctx, s, "Exists", target.table, query, exargs, exargs)
+	err = s.QueryRowContext(ctx, query, exargs...).Scan(&exists)
+	if err != nil {
+		err = formatError("Exists", target.table, "QueryRowContext returned error", query, exargs, err)
+		hooks.finish(-1, err)
+		return false, err
+	}
+	hooks.finish(1, nil)
+
+	return exists, nil
+}
+
 type RowScanner interface {
 	Scan(dest ...interface{}) error
 }
@@ -162,17 +499,87 @@ type Mappable interface {
 	Scan(r RowScanner) error
 }
 
-func if2select(mappableTp reflect.Type) ([]string, string, error) {
-	vp := reflect.Zero(mappableTp)
-	tableMethod := vp.MethodByName("Table")
-	columnsMethod := vp.MethodByName("Columns")
+// ColumnRef is a column name split into its table and column parts.
+// Table is empty for a bare column.
+type ColumnRef struct {
+	Table  string
+	Column string
+}
+
+// ColumnReferer is optionally implemented by Mappable to describe Columns()
+// without guessing from dots in the names. seacle.Generator emits it.
+type ColumnReferer interface {
+	ColumnRefs() []ColumnRef
+}
+
+// selectTarget is what SELECT statements need to know about a Mappable.
+// It is shared through targetCache, so never modify it.
+type selectTarget struct {
+	table   string   // Table() as it is
+	from    string   // quoted table
+	columns []string // quoted columns
+	prefix  string   // "SELECT columns FROM table " to be followed by a fragment
+}
+
+type targetKey struct {
+	tp      reflect.Type
+	dialect Dialect
+}
+
+// targetCache holds *selectTarget for each targetKey. Table() and Columns()
+// are assumed to depend only on the type, as they are called on a zero value.
+var targetCache sync.Map
+
+// if2select returns the selectTarget of mappableTp for the dialect d.
+func if2select(d Dialect, mappableTp reflect.Type) (*selectTarget, error) {
+	key := targetKey{tp: mappableTp, dialect: d}
+	// a dialect which is not comparable cannot be a key
+	cacheable := d == nil || reflect.TypeOf(d).Comparable()
+	if cacheable {
+		if v, ok := targetCache.Load(key); ok {
+			return v.(*selectTarget), nil
+		}
+	}
 
-	tableValue := tableMethod.Call(nil)
-	columnsValue := columnsMethod.Call(nil)
+	vp := reflect.Zero(mappableTp)
+	mappable, ok := vp.Interface().(Mappable)
+	if !ok {
+		return nil, fmt.Errorf("%s is not Mappable", mappableTp.String())
+	}
+	target, err := mappableColumns(d, mappable)
+	if err != nil {
+		return nil, err
+	}
+	if cacheable {
+		// share the one stored first when raced
+		v, _ := targetCache.LoadOrStore(key, target)
+		return v.(*selectTarget), nil
+	}
+	return target, nil
+}
 
-	cols := columnsValue[0].Interface()
+// mappableColumns returns the selectTarget of m for the dialect d.
+func mappableColumns(d Dialect, m Mappable) (*selectTarget, error) {
+	cols := m.Columns()
+	target := &selectTarget{
+		table: m.Table(),
+		from:  quoteName(d, m.Table()),
+	}
 
-	return cols.([]string), tableValue[0].String(), nil
+	if referer, ok := m.(ColumnReferer); ok {
+		refs := referer.ColumnRefs()
+		if len(refs) != len(cols) {
+			return nil, fmt.Errorf("ColumnRefs() and Columns() have different length: %d != %d", len(refs), len(cols))
+		}
+		target.columns = make([]string, 0, len(refs))
+		for _, v := range refs {
+			target.columns = append(target.columns, quoteRef(d, v))
+		}
+	} else {
+		target.columns = quoteNames(d, cols)
+	}
+	target.prefix = fmt.Sprintf("SELECT %s FROM %s ", strings.Join(target.columns, ", "), target.from)
+	return target, nil
 }
 
 type Executable interface {
@@ -189,7 +596,9 @@ type Modifiable interface {
 	AutoIncrementColumn() string
 }
 
-func Insert(ctx Context, e Executable, in Modifiable) (int64, error) {
+// insertColumns returns columns and values of in without the auto increment
+// column.
+func insertColumns(in Modifiable) ([]string, []interface{}) {
 	columns := in.PrimaryKeys()
 	columns = append(columns, in.ValueColumns()...)
 	args := in.PrimaryValues()
@@ -207,76 +616,280 @@ func Insert(ctx Context, e Executable, in Modifiable) (int64, error) {
 		columns = tmpColumns
 		args = tmpArgs
 	}
+	return columns, args
+}
+
+func Insert(ctx Context, e Executable, in Modifiable) (int64, error) {
+	columns, args := insertColumns(in)
 
-	q := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (?)`, in.Table(), strings.Join(columns, ","))
-	query, exargs := expandPlaceholder(q, args)
+	d := dialectOf(e)
+	q := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (?)`, quoteName(d, in.Table()), strings.Join(quoteNames(d, columns), ","))
 
+	if d.SupportsReturning() {
+		// the driver may not support LastInsertId (e.g. PostgreSQL)
+		return insertReturning(ctx, e, d, in, q, columns, args)
+	}
+
+	query, exargs, err := expandPlaceholder(d, q, args)
+	if err != nil {
+		return 0, fmt.Errorf("Insert: %w", err)
+	}
+	query = annotate(ctx, d, query)
+
+	redacted := redactColumns(in, columns, exargs)
+	ctx, hooks := startQuery(ctx, e, "Insert", in.Table(), query, exargs, redacted)
 	result, err := e.ExecContext(ctx, query, exargs...)
 	if err != nil {
-		return 0, formatError("Insert: ExecContext returned error", query, exargs, err)
+		err = formatError("Insert", in.Table(), "ExecContext returned error", query, redacted, err)
+		hooks.finish(-1, err)
+		return 0, err
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		return 0, formatError("Insert: Failed to get LastInsertId", query, exargs, err)
+		err = formatError("Insert", in.Table(), "Failed to get LastInsertId", query, redacted, err)
+		hooks.finish(-1, err)
+		return 0, err
 	}
+	hooks.finish(rowsAffected(result), nil)
 	return id, err
 }
 
-// TODO
-// func BulkInsert(ctx Context, e Executable, in []Modifiable) (int64, error) {
-// }
+func insertReturning(ctx Context, e Executable, d Dialect, in Modifiable, q string, columns []string, args []interface{}) (int64, error) {
+	table := in.Table()
+	autoIncrementCol := in.AutoIncrementColumn()
+	if autoIncrementCol == "" {
+		// nothing to return
+		query, exargs, err := expandPlaceholder(d, q, args)
+		if err != nil {
+			return 0, fmt.Errorf("Insert: %w", err)
+		}
+		query = annotate(ctx, d, query)
+		redacted := redactColumns(in, columns, exargs)
+		ctx, hooks := startQuery(ctx, e, "Insert", table, query, exargs, redacted)
+		result, err := e.ExecContext(ctx, query, exargs...)
+		if err != nil {
+			err = formatError("Insert", table, "ExecContext returned error", query, redacted, err)
+			hooks.finish(-1, err)
+			return 0, err
+		}
+		hooks.finish(rowsAffected(result), nil)
+		return 0, nil
+	}
+
+	q = fmt.Sprintf("%s RETURNING %s", q, quoteName(d, autoIncrementCol))
+	query, exargs, err := expandPlaceholder(d, q, args)
+	if err != nil {
+		return 0, fmt.Errorf("Insert: %w", err)
+	}
+	query = annotate(ctx, d, query)
+
+	var id int64
+	redacted := redactColumns(in, columns, exargs)
+	ctx, hooks := startQuery(ctx, e, "Insert", table, query, exargs, redacted)
+	err = e.QueryRowContext(ctx, query, exargs...).Scan(&id)
+	if err != nil {
+		err = formatError("Insert", table, "QueryRowContext returned error", query, redacted, err)
+		hooks.finish(-1, err)
+		return 0, err
+	}
+	hooks.finish(1, nil)
+	return id, nil
+}
+
+// BulkInsert inserts all of in with multi-row INSERT statements. Rows are
+// split into chunks so that no statement exceeds the bind parameter limit of
+// the dialect, thus the insertion is not atomic unless e is a transaction.
+// It returns the number of inserted rows and the auto increment IDs in the
+// order of in. IDs are returned only for SQLite, and nil for others: MySQL
+// spaces IDs by auto_increment_increment and may interleave them with other
+// sessions, and RETURNING of PostgreSQL does not guarantee the order of rows.
+func BulkInsert(ctx Context, e Executable, in []Modifiable) (int64, []int64, error) {
+	if len(in) == 0 {
+		return 0, nil, nil
+	}
+
+	table := in[0].Table()
+	columns, _ := insertColumns(in[0])
+	rows := make([][]interface{}, 0, len(in))
+	for i, v := range in {
+		cols, args := insertColumns(v)
+		if v.Table() != table {
+			return 0, nil, fmt.Errorf("BulkInsert: in[%d] has different table: %s != %s", i, v.Table(), table)
+		}
+		if strings.Join(cols, ",") != strings.Join(columns, ",") {
+			return 0, nil, fmt.Errorf("BulkInsert: in[%d] has different columns: [%s] != [%s]",
+				i, strings.Join(cols, ", "), strings.Join(columns, ", "))
+		}
+		rows = append(rows, args)
+	}
+
+	d := dialectOf(e)
+	chunkSize := len(in)
+	if len(columns) > 0 && d.MaxParams() > 0 {
+		chunkSize = d.MaxParams() / len(columns)
+		if chunkSize < 1 {
+			return 0, nil, fmt.Errorf("BulkInsert: too many columns for %s: %d", d.Name(), len(columns))
+		}
+	}
+
+	var affected int64
+	ids := []int64{}
+	for start := 0; start < len(rows); start += chunkSize {
+		end := start + chunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		n, chunkIDs, err := bulkInsertChunk(ctx, e, d, in[0], columns, rows[start:end])
+		affected += n
+		if err != nil {
+			return affected, nil, err
+		}
+		if ids != nil && chunkIDs != nil {
+			ids = append(ids, chunkIDs...)
+		} else {
+			ids = nil
+		}
+	}
+
+	return affected, ids, nil
+}
+
+// bulkInsertChunk inserts rows with a statement. proto is one of the
+// inserted Modifiables, and used for the table and its metadata.
+func bulkInsertChunk(ctx Context, e Executable, d Dialect, proto Modifiable, columns []string, rows [][]interface{}) (affected int64, ids []int64, err error) {
+	table := proto.Table()
+	autoIncrementCol := proto.AutoIncrementColumn()
+	values := strings.TrimSuffix(strings.Repeat("(?),", len(rows)), ",")
+	q := fmt.Sprintf(`INSERT INTO %s (%s) VALUES %s`, quoteName(d, table), strings.Join(quoteNames(d, columns), ","), values)
+	args := make([]interface{}, 0, len(rows))
+	for _, v := range rows {
+		args = append(args, v)
+	}
+
+	query, exargs, err := expandPlaceholder(d, q, args...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("BulkInsert: %w", err)
+	}
+	query = annotate(ctx, d, query)
+	redacted := redactColumns(proto, columns, exargs)
+	ctx, hooks := startQuery(ctx, e, "BulkInsert", table, query, exargs, redacted)
+	defer func() { hooks.finish(affected, err) }()
+	result, err := e.ExecContext(ctx, query, exargs...)
+	if err != nil {
+		return 0, nil, formatError("BulkInsert", table, "ExecContext returned error", query, redacted, err)
+	}
+
+	affected, err = result.RowsAffected()
+	if err != nil {
+		return 0, nil, formatError("BulkInsert", table, "Failed to get RowsAffected", query, redacted, err)
+	}
+
+	if autoIncrementCol == "" {
+		return affected, nil, nil
+	}
+	ider, ok := d.(bulkInsertIDer)
+	if !ok {
+		return affected, nil, nil
+	}
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		// the driver cannot tell; inserted rows are still counted
+		return affected, nil, nil
+	}
+	return affected, ider.bulkInsertIDs(lastInsertID, len(rows)), nil
+}
 
 func Update(ctx Context, e Executable, in Modifiable) error {
+	d := dialectOf(e)
 	pkey := in.PrimaryKeys()
 	kv := []string{}
 	for _, v := range pkey {
-		kv = append(kv, fmt.Sprintf("%s = ?", v))
+		kv = append(kv, fmt.Sprintf("%s = ?", quoteName(d, v)))
 	}
 	cond := strings.Join(kv, " AND ")
 
 	cols := in.ValueColumns()
 	kv = make([]string, 0)
 	for _, v := range cols {
-		kv = append(kv, fmt.Sprintf("%s = ?", v))
+		kv = append(kv, fmt.Sprintf("%s = ?", quoteName(d, v)))
 	}
 	set := strings.Join(kv, ", ")
 
-	query := fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, in.Table(), set, cond)
+	query := annotate(ctx, d, rebind(d, fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, quoteName(d, in.Table()), set, cond)))
 	exargs := in.Values()
 	exargs = append(exargs, in.PrimaryValues()...)
 
-	_, err := e.ExecContext(ctx, query, exargs...)
+	redacted := redactColumns(in, append(cols, pkey...), exargs)
+	ctx, hooks := startQuery(ctx, e, "Update", in.Table(), query, exargs, redacted)
+	result, err := e.ExecContext(ctx, query, exargs...)
 	if err != nil {
-		return formatError("Update: ExecContext returned error", query, exargs, err)
+		err = formatError("Update", in.Table(), "ExecContext returned error", query, redacted, err)
+		hooks.finish(-1, err)
+		return err
 	}
+	hooks.finish(rowsAffected(result), nil)
 	return nil
 }
 
 func Delete(ctx Context, e Executable, in Modifiable) error {
+	d := dialectOf(e)
 	pkey := in.PrimaryKeys()
 	kv := []string{}
 	for _, v := range pkey {
-		kv = append(kv, fmt.Sprintf("%s = ?", v))
+		kv = append(kv, fmt.Sprintf("%s = ?", quoteName(d, v)))
 	}
 	cond := strings.Join(kv, " AND ")
 
-	query := fmt.Sprintf(`DELETE FROM %s WHERE %s`, in.Table(), cond)
+	query := annotate(ctx, d, rebind(d, fmt.Sprintf(`DELETE FROM %s WHERE %s`, quoteName(d, in.Table()), cond)))
 	exargs := in.PrimaryValues()
 
-	_, err := e.ExecContext(ctx, query, exargs...)
+	redacted := redactColumns(in, pkey, exargs)
+	ctx, hooks := startQuery(ctx, e, "Delete", in.Table(), query, exargs, redacted)
+	result, err := e.ExecContext(ctx, query, exargs...)
 	if err != nil {
-		return formatError("Delete: ExecContext returned error", query, exargs, err)
+		err = formatError("Delete", in.Table(), "ExecContext returned error", query, redacted, err)
+		hooks.finish(-1, err)
+		return err
 	}
+	hooks.finish(rowsAffected(result), nil)
 	return nil
 }
 
-func formatError(message, query string, args []interface{}, err error) error {
-	argsstr := make([]string, 0, len(args))
-	for _, v := range args {
+// QueryError is returned when a statement issued by seacle fails.
+type QueryError struct {
+	Op      string // seacle function like "Select" or "Insert"
+	Table   string
+	Message string // what failed
+	Query   string
+	Args    []interface{} // sensitive ones are replaced with Redacted
+	Err     error         // error returned from the driver
+}
+
+func (e *QueryError) Error() string {
+	argsstr := make([]string, 0, len(e.Args))
+	for _, v := range e.Args {
 		argsstr = append(argsstr, fmt.Sprintf(`"%v"`, v))
 	}
 
-	return fmt.Errorf(`%s: err="%s", query="%s", args=%s`,
-		message, err, query, "["+strings.Join(argsstr, ", ")+"]")
+	return fmt.Sprintf(`%s: %s: err="%s", query="%s", args=%s`,
+		e.Op, e.Message, e.Err, e.Query, "["+strings.Join(argsstr, ", ")+"]")
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// formatError makes a QueryError. Args are redacted by the global
+// RedactionPolicy, so callers which know secret columns should pass args
+// through redactColumns in advance.
+func formatError(op, table, message, query string, args []interface{}, err error) error {
+	return &QueryError{
+		Op:      op,
+		Table:   table,
+		Message: message,
+		Query:   query,
+		Args:    RedactArgs(query, args),
+		Err:     err,
+	}
 }
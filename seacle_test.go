@@ -3,13 +3,16 @@ package seacle
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 var db *sql.DB
@@ -124,6 +127,20 @@ func TestSelect(t *testing.T) {
 	if err.Error() != `Select: QueryContext returned error: err="no such column: naname", query="SELECT person.id, person.name, person.created_at FROM person WHERE naname = ?", args=["Lamimi"]` {
 		t.Errorf("unexpect error: %s", err)
 	}
+	var qerr *QueryError
+	if !errors.As(err, &qerr) {
+		t.Fatalf("error is not QueryError: %T", err)
+	}
+	if qerr.Op != "Select" || qerr.Table != "person" || qerr.Query != "SELECT person.id, person.name, person.created_at FROM person WHERE naname = ?" {
+		t.Errorf("unexpected QueryError: %#v", qerr)
+	}
+	if len(qerr.Args) != 1 || qerr.Args[0] != "Lamimi" {
+		t.Errorf("unexpected args: %v", qerr.Args)
+	}
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.Code != sqlite3.ErrError {
+		t.Errorf("driver error is not unwrapped: %v", errors.Unwrap(err))
+	}
 
 	// success
 	pepple := []Person{}
@@ -165,6 +182,178 @@ func TestSelectWhereIn(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expect error")
 	}
+	if !errors.Is(err, ErrArgumentCount) {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err.Error() != `Select: seacle: number of placeholders and arguments mismatch: 2 placeholders for 1 arguments: query="SELECT person.id, person.name, person.created_at FROM person WHERE name IN (?) AND id = ?"` {
+		t.Errorf("unexpected error message: %s", err)
+	}
+
+	// fail (empty slice)
+	people = make([]*Person, 0)
+	err = Select(ctx, conn, &people, `WHERE name IN (?)`, []string{})
+	if !errors.Is(err, ErrEmptySlice) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestExpandPlaceholderStrict(t *testing.T) {
+	_, _, err := expandPlaceholder(Generic, `WHERE id = ?`, 1, 2)
+	if err == nil || err.Error() != `seacle: number of placeholders and arguments mismatch: 1 placeholders for 2 arguments: query="WHERE id = ?"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, _, err = expandPlaceholder(Generic, `WHERE (id, name) IN (?)`, []Tuple{})
+	if err == nil || err.Error() != `seacle: empty slice is given for placeholder: args[0]: query="WHERE (id, name) IN (?)"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// placeholders in literals are not counted
+	query, args, err := expandPlaceholder(Generic, `WHERE note = 'why?' AND id = ?`, 1)
+	if err != nil || query != `WHERE note = 'why?' AND id = ?` || len(args) != 1 {
+		t.Errorf("unexpected result: %s, %v, %v", query, args, err)
+	}
+
+	row := QueryRow(context.Background(), db, `SELECT name FROM person WHERE id = ?`)
+	if !errors.Is(row.Err(), ErrArgumentCount) {
+		t.Errorf("unexpected error: %v", row.Err())
+	}
+	var name string
+	if err := row.Scan(&name); !errors.Is(err, ErrArgumentCount) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// *sql.Row cannot carry the error, so the query is not issued
+	calls := []string{}
+	rec := &hookRecorder{name: "strict", calls: &calls}
+	err = QueryRowContext(context.Background(), WithHooks(db, rec), `SELECT name FROM person WHERE id = :id`, Named(map[string]interface{}{})).Scan(&name)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(rec.events) != 1 || rec.events[0].Err == nil || !strings.HasPrefix(rec.events[0].Err.Error(), "QueryRowContext: named parameter id is not given") {
+		t.Errorf("unexpected events: %+v", rec.events)
+	}
+}
+
+func TestSelectEach(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Errorf("failed to checkout connection: %s", err.Error())
+	}
+
+	names := []string{}
+	err = SelectEach(ctx, conn, (*Person)(nil), func(m Mappable) error {
+		names = append(names, m.(*Person).Name)
+		return nil
+	}, `WHERE id IN (?) ORDER BY id`, []int{1, 2, 3})
+	if err != nil {
+		t.Errorf("failed to iterate: %s", err)
+	}
+	if strings.Join(names, ",") != "Alberto,Lamimi,Naillebert" {
+		t.Errorf("unexpected names: %v", names)
+	}
+
+	// stop early
+	count := 0
+	err = SelectEach(ctx, conn, (*Person)(nil), func(m Mappable) error {
+		count++
+		if count == 2 {
+			return ErrStop
+		}
+		return nil
+	}, `ORDER BY id`)
+	if err != nil {
+		t.Errorf("failed to iterate: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("unexpected count: %d", count)
+	}
+
+	// wrapped ErrStop
+	err = SelectEach(ctx, conn, (*Person)(nil), func(m Mappable) error {
+		return fmt.Errorf("enough: %w", ErrStop)
+	}, ``)
+	if err != nil {
+		t.Errorf("failed to iterate: %s", err)
+	}
+
+	// error from callback, which is not an error of the query
+	calls := []string{}
+	rec := &hookRecorder{name: "local", calls: &calls}
+	errCallback := errors.New("callback error")
+	err = SelectEach(ctx, WithHooks(conn, rec), (*Person)(nil), func(m Mappable) error {
+		return errCallback
+	}, ``)
+	if err != errCallback {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(rec.events) != 1 || rec.events[0].Err != nil || rec.events[0].RowsAffected != 1 {
+		t.Errorf("unexpected events: %+v", rec.events)
+	}
+
+	// the connection is still usable after stopping early
+	person := &Person{}
+	err = SelectRow(ctx, conn, person, `WHERE name = ?`, "Lamimi")
+	if err != nil {
+		t.Errorf("Lamimi is not found: %s", err.Error())
+	}
+}
+
+func TestExpandPlaceholderTuple(t *testing.T) {
+	tests := []struct {
+		query    string
+		args     []interface{}
+		expected string
+		exargs   []interface{}
+	}{
+		{
+			"WHERE (id, name) IN (?)",
+			[]interface{}{[]Tuple{{1, "a"}, {2, "b"}}},
+			"WHERE (id, name) IN ((?,?),(?,?))",
+			[]interface{}{1, "a", 2, "b"},
+		},
+		{
+			"WHERE (id, name) IN (?) AND age > ?",
+			[]interface{}{[][]interface{}{{1, "a"}}, 20},
+			"WHERE (id, name) IN ((?,?)) AND age > ?",
+			[]interface{}{1, "a", 20},
+		},
+		{
+			"WHERE (id, name) = ?",
+			[]interface{}{Tuple{1, "a"}},
+			"WHERE (id, name) = (?,?)",
+			[]interface{}{1, "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		query, exargs, err := expandPlaceholder(Generic, tt.query, tt.args...)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if query != tt.expected {
+			t.Errorf("unexpected query: %s", query)
+		}
+		if !reflect.DeepEqual(exargs, tt.exargs) {
+			t.Errorf("unexpected args: %v", exargs)
+		}
+	}
+
+	query, _, _ := expandPlaceholder(PostgreSQL, "WHERE (id, name) IN (?) AND age > ?", []Tuple{{1, "a"}, {2, "b"}}, 20)
+	if query != "WHERE (id, name) IN (($1,$2),($3,$4)) AND age > $5" {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	ctx := context.Background()
+	people := []*Person{}
+	err := Select(ctx, db, &people, `WHERE (id, name) IN (?)`, []Tuple{{1, "Alberto"}, {2, "Alberto"}, {3, "Naillebert"}})
+	if err != nil {
+		t.Errorf("failed to select: %s", err)
+	}
+	if len(people) != 2 {
+		t.Errorf("len(people) != 2")
+	}
 }
 
 func TestSelectRow(t *testing.T) {
@@ -188,6 +377,68 @@ func TestSelectRow(t *testing.T) {
 	}
 }
 
+func TestCountExists(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Errorf("failed to checkout connection: %s", err.Error())
+	}
+
+	count, err := Count(ctx, conn, (*Person)(nil), `WHERE name LIKE ?`, "%ber%")
+	if err != nil {
+		t.Errorf("failed to count: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("unexpected count: %d", count)
+	}
+
+	count, err = Count(ctx, conn, (*Person)(nil), `WHERE name IN (?)`, []string{"Alberto", "Lamimi", "Emet-Selch"})
+	if err != nil {
+		t.Errorf("failed to count: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("unexpected count: %d", count)
+	}
+
+	exists, err := Exists(ctx, WithDialect(conn, SQLite), (*Person)(nil), `WHERE name = ?`, "Lamimi")
+	if err != nil {
+		t.Errorf("failed to check existence: %s", err)
+	}
+	if !exists {
+		t.Errorf("Lamimi does not exist")
+	}
+
+	exists, err = Exists(ctx, conn, (*Person)(nil), `WHERE name = ?`, "Emet-Selch")
+	if err != nil {
+		t.Errorf("failed to check existence: %s", err)
+	}
+	if exists {
+		t.Errorf("Emet-Selch exists")
+	}
+
+	// fail (invalid query)
+	_, err = Count(ctx, conn, (*Person)(nil), `WHERE naname = ?`, "Lamimi")
+	if err == nil || err.Error() != `Count: QueryRowContext returned error: err="no such column: naname", query="SELECT COUNT(*) FROM person WHERE naname = ?", args=["Lamimi"]` {
+		t.Errorf("unexpect error: %s", err)
+	}
+
+	// sql.ErrNoRows is reachable through QueryError
+	_, err = Count(ctx, conn, (*Person)(nil), `GROUP BY id HAVING id < 0`)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("unexpect error: %s", err)
+	}
+
+	// fail (untyped nil)
+	_, err = Count(ctx, conn, nil, `WHERE name = ?`, "Lamimi")
+	if err == nil || err.Error() != "Count: m is not pointer of Mappable: <nil>" {
+		t.Errorf("unexpect error: %v", err)
+	}
+	_, err = Exists(ctx, conn, nil, `WHERE name = ?`, "Lamimi")
+	if err == nil || err.Error() != "Exists: m is not pointer of Mappable: <nil>" {
+		t.Errorf("unexpect error: %v", err)
+	}
+}
+
 func TestInsertDelete(t *testing.T) {
 	ctx := context.Background()
 	conn, err := db.Conn(ctx)
@@ -266,3 +517,212 @@ func TestUpdate(t *testing.T) {
 		t.Errorf("unexpected created_at, actual=%v", updatedPerson.CreatedAt)
 	}
 }
+
+// recorder is an Executable which records issued queries.
+type recorder struct {
+	Executable
+	queries []string
+}
+
+func (r *recorder) QueryContext(ctx Context, query string, args ...interface{}) (*sql.Rows, error) {
+	r.queries = append(r.queries, query)
+	return r.Executable.QueryContext(ctx, query, args...)
+}
+
+func (r *recorder) QueryRowContext(ctx Context, query string, args ...interface{}) *sql.Row {
+	r.queries = append(r.queries, query)
+	return r.Executable.QueryRowContext(ctx, query, args...)
+}
+
+func (r *recorder) ExecContext(ctx Context, query string, args ...interface{}) (sql.Result, error) {
+	r.queries = append(r.queries, query)
+	return r.Executable.ExecContext(ctx, query, args...)
+}
+
+// SQLite 3.35+ understands RETURNING
+var returningSQLite = &dialect{
+	name:        "sqlite-returning",
+	placeholder: questionPlaceholder,
+	quote:       enclosedQuote(`"`, `"`),
+	returning:   true,
+	maxParams:   999,
+	upsert:      onConflictDoUpdate,
+}
+
+func TestInsertReturning(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Errorf("failed to checkout connection: %s", err.Error())
+	}
+
+	rec := &recorder{Executable: conn}
+	tm, _ := time.Parse("2006-01-02 15:04:05", "2020-07-08 10:00:00")
+	person := &Person{
+		Name:      "Hythlodaeus",
+		CreatedAt: tm,
+	}
+	id, err := Insert(ctx, WithDialect(rec, returningSQLite), person)
+	if err != nil {
+		t.Fatalf("failed to insert Hythlodaeus: %s", err.Error())
+	}
+	if len(rec.queries) != 1 || rec.queries[0] != `INSERT INTO "person" ("name","created_at") VALUES (?,?) RETURNING "id"` {
+		t.Errorf("unexpected queries: %v", rec.queries)
+	}
+
+	newPerson := &Person{}
+	err = SelectRow(ctx, conn, newPerson, `WHERE name = ?`, person.Name)
+	if err != nil {
+		t.Fatalf("failed to fetch newPerson: %s", err)
+	}
+	if newPerson.ID != id {
+		t.Errorf("unexpected ID, actual=%d, expected=%d", newPerson.ID, id)
+	}
+
+	err = Delete(ctx, conn, newPerson)
+	if err != nil {
+		t.Errorf("failed to delete newPerson: %s", err)
+	}
+
+	// LastInsertId path
+	rec = &recorder{Executable: conn}
+	id, err = Insert(ctx, WithDialect(rec, SQLite), person)
+	if err != nil {
+		t.Fatalf("failed to insert Hythlodaeus: %s", err.Error())
+	}
+	if len(rec.queries) != 1 || rec.queries[0] != `INSERT INTO "person" ("name","created_at") VALUES (?,?)` {
+		t.Errorf("unexpected queries: %v", rec.queries)
+	}
+	person.ID = id
+	err = Delete(ctx, conn, person)
+	if err != nil {
+		t.Errorf("failed to delete person: %s", err)
+	}
+}
+
+func TestBulkInsert(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Errorf("failed to checkout connection: %s", err.Error())
+	}
+
+	tm, _ := time.Parse("2006-01-02 15:04:05", "2020-07-09 10:00:00")
+	in := make([]Modifiable, 0, 1200)
+	for i := 0; i < 1200; i++ {
+		in = append(in, &Person{
+			Name:      fmt.Sprintf("Bulk-%04d", i),
+			CreatedAt: tm,
+		})
+	}
+
+	rec := &recorder{Executable: conn}
+	affected, ids, err := BulkInsert(ctx, WithDialect(rec, SQLite), in)
+	if err != nil {
+		t.Fatalf("failed to bulk insert: %s", err)
+	}
+	defer conn.ExecContext(ctx, `DELETE FROM person WHERE name LIKE 'Bulk-%'`)
+
+	if affected != 1200 {
+		t.Errorf("unexpected affected rows: %d", affected)
+	}
+	// 999 params / 2 columns = 499 rows per statement
+	if len(rec.queries) != 3 {
+		t.Errorf("unexpected number of statements: %d", len(rec.queries))
+	}
+	if len(ids) != 1200 {
+		t.Fatalf("unexpected number of ids: %d", len(ids))
+	}
+
+	for _, i := range []int{0, 498, 499, 1199} {
+		person := &Person{}
+		err = SelectRow(ctx, conn, person, `WHERE id = ?`, ids[i])
+		if err != nil {
+			t.Errorf("failed to fetch ids[%d]: %s", i, err)
+		}
+		if person.Name != fmt.Sprintf("Bulk-%04d", i) {
+			t.Errorf("unexpected name for ids[%d]: %s", i, person.Name)
+		}
+	}
+
+	// a dialect which cannot tell the IDs returns nil
+	rec = &recorder{Executable: conn}
+	affected, ids, err = BulkInsert(ctx, rec, in[:3])
+	if err != nil {
+		t.Fatalf("failed to bulk insert: %s", err)
+	}
+	if affected != 3 || ids != nil {
+		t.Errorf("unexpected result: affected=%d, ids=%v", affected, ids)
+	}
+	if len(rec.queries) != 1 || rec.queries[0] != `INSERT INTO person (name,created_at) VALUES (?,?),(?,?),(?,?)` {
+		t.Errorf("unexpected queries: %v", rec.queries)
+	}
+
+	// fail (different table)
+	_, _, err = BulkInsert(ctx, conn, []Modifiable{&Person{}, &Group{}})
+	if err == nil || err.Error() != "BulkInsert: in[1] has different table: group != person" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// empty
+	affected, ids, err = BulkInsert(ctx, conn, nil)
+	if affected != 0 || ids != nil || err != nil {
+		t.Errorf("unexpected result: affected=%d, ids=%v, err=%v", affected, ids, err)
+	}
+}
+
+func TestSelectTargetCache(t *testing.T) {
+	tp := reflect.TypeOf(&Person{})
+	targets := make(chan *selectTarget, 10)
+	for i := 0; i < cap(targets); i++ {
+		go func() {
+			target, err := if2select(MySQL, tp)
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			targets <- target
+		}()
+	}
+	first := <-targets
+	for i := 1; i < cap(targets); i++ {
+		if target := <-targets; target != first {
+			t.Errorf("target is not cached: %p != %p", target, first)
+		}
+	}
+	if first.prefix != "SELECT `person`.`id`, `person`.`name`, `person`.`created_at` FROM `person` " {
+		t.Errorf("unexpected prefix: %s", first.prefix)
+	}
+
+	// cached for each dialect
+	target, err := if2select(PostgreSQL, tp)
+	if err != nil || target.prefix != `SELECT "person"."id", "person"."name", "person"."created_at" FROM "person" ` {
+		t.Errorf("unexpected target: %v, %v", target, err)
+	}
+}
+
+func BenchmarkIf2select(b *testing.B) {
+	tp := reflect.TypeOf(&Person{})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if2select(MySQL, tp)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			mappableColumns(MySQL, reflect.Zero(tp).Interface().(Mappable))
+		}
+	})
+}
+
+func BenchmarkSelectRow(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := SelectRow(ctx, db, &Person{}, `WHERE id = ?`, 1)
+		if err != nil {
+			b.Fatalf("failed to select: %s", err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package seacletest
+
+import (
+	"database/sql"
+
+	"github.com/acidlemon/seacle"
+)
+
+type Item struct {
+	ID   int64  `db:"id,primary,auto_increment"`
+	Name string `db:"name"`
+}
+
+func (p *Item) Table() string {
+	return "item"
+}
+
+func (p *Item) Columns() []string {
+	return []string{"item.id", "item.name"}
+}
+
+func (p *Item) PrimaryKeys() []string {
+	return []string{"id"}
+}
+
+func (p *Item) PrimaryValues() []interface{} {
+	return []interface{}{p.ID}
+}
+
+func (p *Item) ValueColumns() []string {
+	return []string{"name"}
+}
+
+func (p *Item) Values() []interface{} {
+	return []interface{}{p.Name}
+}
+
+func (p *Item) AutoIncrementColumn() string {
+	return "id"
+}
+
+func (p *Item) Scan(r seacle.RowScanner) error {
+	var arg0 int64
+	var arg1 string
+
+	err := r.Scan(&arg0, &arg1)
+	if err == sql.ErrNoRows {
+		return err
+	} else if err != nil {
+		// something wrong
+		return err
+	}
+
+	p.ID = arg0
+	p.Name = arg1
+
+	return nil
+}
@@ -0,0 +1,89 @@
+// Package seacletest provides helpers for testing code using seacle.
+package seacletest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/acidlemon/seacle"
+)
+
+var _ seacle.Tracer = (*Recorder)(nil)
+
+// Recorder is a seacle.Tracer which keeps spans in memory.
+//
+//	rec := &seacletest.Recorder{}
+//	h := seacle.WithTracer(db, rec)
+//	// ... run statements on h
+//	spans := rec.Spans()
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// RecordedSpan is a span recorded by Recorder.
+type RecordedSpan struct {
+	Name       string
+	Attributes map[string]interface{}
+	Errors     []error
+	Ended      bool
+
+	mu *sync.Mutex
+}
+
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, seacle.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	span := &RecordedSpan{
+		Name:       name,
+		Attributes: map[string]interface{}{},
+		mu:         &r.mu,
+	}
+	r.spans = append(r.spans, span)
+	return ctx, span
+}
+
+// Spans returns copies of spans recorded so far, in started order.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]RecordedSpan, 0, len(r.spans))
+	for _, v := range r.spans {
+		span := *v
+		span.Attributes = make(map[string]interface{}, len(v.Attributes))
+		for key, value := range v.Attributes {
+			span.Attributes[key] = value
+		}
+		span.Errors = append([]error(nil), v.Errors...)
+		span.mu = nil
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// Reset forgets recorded spans.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.spans = nil
+	r.mu.Unlock()
+}
+
+func (s *RecordedSpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	s.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+func (s *RecordedSpan) RecordError(err error) {
+	s.mu.Lock()
+	s.Errors = append(s.Errors, err)
+	s.mu.Unlock()
+}
+
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	s.Ended = true
+	s.mu.Unlock()
+}
@@ -0,0 +1,111 @@
+package seacletest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/acidlemon/seacle"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open: %s", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	_, err = db.ExecContext(ctx, `CREATE TABLE item (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(80))`)
+	if err != nil {
+		t.Fatalf("failed to create table: %s", err)
+	}
+
+	rec := &Recorder{}
+	h := seacle.WithTracer(db, rec)
+
+	_, err = seacle.Insert(ctx, h, &Item{Name: "potion"})
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+	items := []*Item{}
+	err = seacle.Select(ctx, h, &items, `WHERE name = ?`, "potion")
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+	_, err = seacle.QueryContext(ctx, h, `SELECT * FROM no_table`)
+	if err == nil {
+		t.Fatalf("error is expected")
+	}
+
+	spans := rec.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("unexpected spans: %+v", spans)
+	}
+	expected := []struct {
+		name      string
+		statement string
+		op        string
+		table     interface{}
+	}{
+		{"Insert item", "INSERT INTO item (name) VALUES (?)", "Insert", "item"},
+		{"Select item", "SELECT item.id, item.name FROM item WHERE name = ?", "Select", "item"},
+		{"QueryContext", "SELECT * FROM no_table", "QueryContext", nil},
+	}
+	for i, span := range spans {
+		e := expected[i]
+		if span.Name != e.name || !span.Ended ||
+			span.Attributes[seacle.AttrStatement] != e.statement ||
+			span.Attributes[seacle.AttrOperation] != e.op ||
+			span.Attributes[seacle.AttrTable] != e.table {
+			t.Errorf("unexpected span: %+v", span)
+		}
+	}
+	if len(spans[0].Errors) != 0 || len(spans[2].Errors) != 1 {
+		t.Errorf("unexpected errors: %v, %v", spans[0].Errors, spans[2].Errors)
+	}
+
+	rec.Reset()
+	if len(rec.Spans()) != 0 {
+		t.Errorf("spans are not reset")
+	}
+
+	// sql.ErrNoRows wrapped in QueryError is not an error of the span
+	_, err = seacle.Count(ctx, h, &Item{}, `GROUP BY id HAVING id < 0`)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spans := rec.Spans(); len(spans) != 1 || len(spans[0].Errors) != 0 {
+		t.Errorf("unexpected spans: %+v", spans)
+	}
+}
+
+func TestRecorderNested(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open: %s", err)
+	}
+	defer db.Close()
+
+	global := &Recorder{}
+	seacle.AddHook(seacle.TracingHook(global))
+	defer seacle.SetHooks()
+	local := &Recorder{}
+	h := seacle.WithTracer(db, local)
+
+	_, err = seacle.QueryContext(ctx, h, `SELECT * FROM no_table`)
+	if err == nil {
+		t.Fatalf("error is expected")
+	}
+
+	// each tracer ends its own span
+	for _, rec := range []*Recorder{global, local} {
+		spans := rec.Spans()
+		if len(spans) != 1 || !spans[0].Ended || len(spans[0].Errors) != 1 {
+			t.Errorf("unexpected spans: %+v", spans)
+		}
+	}
+}
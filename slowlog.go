@@ -0,0 +1,120 @@
+package seacle
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// SlowQuery is a statement reported by SlowQueryLog.
+type SlowQuery struct {
+	QueryEvent
+	// Plan is the output of EXPLAIN of the statement, one line per row.
+	Plan []string
+	// PlanErr is the error of EXPLAIN, if any.
+	PlanErr error
+}
+
+func (q *SlowQuery) String() string {
+	argsstr := make([]string, 0, len(q.Args))
+	for _, v := range q.Args {
+		argsstr = append(argsstr, fmt.Sprintf(`"%v"`, v))
+	}
+	s := fmt.Sprintf(`slow query: %s: duration=%s, query="%s", args=%s`,
+		q.Op, q.Duration, q.Query, "["+strings.Join(argsstr, ", ")+"]")
+	if q.PlanErr != nil {
+		s += fmt.Sprintf(`, plan_err="%s"`, q.PlanErr)
+	} else if len(q.Plan) != 0 {
+		s += "\n\t" + strings.Join(q.Plan, "\n\t")
+	}
+	return s
+}
+
+// SlowQueryLog is a Hook which reports statements taking Threshold or more.
+// The query plan is captured by EXPLAIN (EXPLAIN QUERY PLAN for SQLite) on
+// the same handle just after the statement. A zero Threshold reports every
+// statement, which is handy to look for full table scans in tests.
+//
+//	seacle.AddHook(&seacle.SlowQueryLog{Threshold: 100 * time.Millisecond})
+type SlowQueryLog struct {
+	Threshold time.Duration
+	// NoExplain disables capturing query plans.
+	NoExplain bool
+	// Report is called for each slow query. The default logs it with the
+	// standard logger.
+	Report func(ctx Context, q *SlowQuery)
+}
+
+func (l *SlowQueryLog) BeforeQuery(ctx Context, ev *QueryEvent) Context {
+	return ctx
+}
+
+func (l *SlowQueryLog) AfterQuery(ctx Context, ev *QueryEvent) {
+	if ev.Duration < l.Threshold {
+		return
+	}
+
+	q := &SlowQuery{QueryEvent: *ev}
+	// failed statements may leave transactions unusable, and rows of
+	// QueryContext and QueryRowContext are still open on the connection
+	if !l.NoExplain && ev.Err == nil && ev.handle != nil && ev.Op != "QueryContext" && ev.Op != "QueryRowContext" {
+		q.Plan, q.PlanErr = explain(ctx, ev.handle, ev.Query, ev.rawArgs)
+	}
+
+	if l.Report != nil {
+		l.Report(ctx, q)
+		return
+	}
+	log.Println(q.String())
+}
+
+func explainOf(d Dialect) string {
+	if v, ok := d.(*dialect); ok {
+		return v.explain
+	}
+	return "EXPLAIN"
+}
+
+// explain runs EXPLAIN of query on s and returns rows of the result with
+// columns joined by " | ".
+func explain(ctx Context, s Selectable, query string, args []interface{}) ([]string, error) {
+	prefix := explainOf(dialectOf(s))
+	if prefix == "" {
+		return nil, fmt.Errorf("%s does not support EXPLAIN", dialectOf(s).Name())
+	}
+
+	// EXPLAIN must not push statements out of caches
+	rows, err := uncached(s).QueryContext(ctx, prefix+" "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	plan := []string{}
+	values := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+		line := make([]string, 0, len(values))
+		for _, v := range values {
+			line = append(line, v.String)
+		}
+		plan = append(plan, strings.Join(line, " | "))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
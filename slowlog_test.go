@@ -0,0 +1,123 @@
+package seacle
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowQueryLog(t *testing.T) {
+	ctx := context.Background()
+	reported := []*SlowQuery{}
+	slowlog := &SlowQueryLog{
+		Report: func(ctx Context, q *SlowQuery) {
+			reported = append(reported, q)
+		},
+	}
+	h := WithHooks(WithDialect(db, SQLite), slowlog)
+
+	people := []*Person{}
+	err := Select(ctx, h, &people, `WHERE name = ?`, "Lamimi")
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+	err = SelectRow(ctx, h, &Person{}, `WHERE id = ?`, 1)
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+
+	// explained after the row is scanned
+	var name string
+	err = QueryRow(ctx, h, `SELECT name FROM person WHERE name = ?`, "Lamimi").Scan(&name)
+	if err != nil {
+		t.Fatalf("failed to query: %s", err)
+	}
+	// not explained while the row is not scanned
+	row := QueryRowContext(ctx, h, `SELECT name FROM person WHERE name = ?`, "Lamimi")
+	err = row.Scan(&name)
+	if err != nil {
+		t.Fatalf("failed to query: %s", err)
+	}
+
+	if len(reported) != 4 {
+		t.Fatalf("unexpected number of reports: %d", len(reported))
+	}
+	if q := reported[2]; q.Op != "QueryRow" || !strings.Contains(strings.Join(q.Plan, "\n"), "SCAN person") {
+		t.Errorf("unexpected report: %+v", q)
+	}
+	if q := reported[3]; q.Op != "QueryRowContext" || q.Plan != nil || q.PlanErr != nil {
+		t.Errorf("unexpected report: %+v", q)
+	}
+	if q := reported[0]; q.Query != `SELECT "person"."id", "person"."name", "person"."created_at" FROM "person" WHERE name = ?` ||
+		len(q.Args) != 1 || q.Args[0] != "Lamimi" || q.PlanErr != nil ||
+		!strings.Contains(strings.Join(q.Plan, "\n"), "SCAN person") {
+		t.Errorf("full table scan is not reported: %+v", q)
+	}
+	if q := reported[1]; strings.Contains(strings.Join(q.Plan, "\n"), "SCAN") {
+		t.Errorf("unexpected plan: %v", q.Plan)
+	}
+
+	// not slow
+	slowlog.Threshold = time.Hour
+	err = Select(ctx, h, &people, `WHERE name = ?`, "Lamimi")
+	if err != nil {
+		t.Fatalf("failed to select: %s", err)
+	}
+	if len(reported) != 4 {
+		t.Errorf("unexpected number of reports: %d", len(reported))
+	}
+
+	// the standard logger by default
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+	h = WithHooks(WithDialect(db, SQLite), &SlowQueryLog{})
+	_, err = Count(ctx, h, &Person{}, `WHERE name = ?`, "Lamimi")
+	if err != nil {
+		t.Fatalf("failed to count: %s", err)
+	}
+	if !strings.Contains(buf.String(), `slow query: Count: duration=`) ||
+		!strings.Contains(buf.String(), `query="SELECT COUNT(*) FROM "person" WHERE name = ?", args=["Lamimi"]`) ||
+		!strings.Contains(buf.String(), "SCAN person") {
+		t.Errorf("unexpected log: %s", buf.String())
+	}
+}
+
+func TestSlowQueryLogStmtCache(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+	p := &preparer{Conn: conn}
+	cache := NewStmtCache(WithDialect(p, SQLite), 2)
+	defer cache.Close()
+
+	reported := []*SlowQuery{}
+	slowlog := &SlowQueryLog{
+		Report: func(ctx Context, q *SlowQuery) {
+			reported = append(reported, q)
+		},
+	}
+	h := WithHooks(cache, slowlog)
+	for _, v := range []string{`WHERE id = ?`, `WHERE name = ?`, `WHERE id = ?`} {
+		people := []*Person{}
+		err := Select(ctx, h, &people, v, 1)
+		if err != nil {
+			t.Fatalf("failed to select: %s", err)
+		}
+	}
+
+	if len(reported) != 3 || reported[0].Plan == nil {
+		t.Fatalf("unexpected reports: %+v", reported)
+	}
+	// EXPLAIN is neither prepared nor cached
+	if len(p.prepared) != 2 || cache.Len() != 2 {
+		t.Errorf("unexpected prepared statements: %v", p.prepared)
+	}
+}
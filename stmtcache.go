@@ -0,0 +1,409 @@
+package seacle
+
+import (
+	"container/list"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+)
+
+// DefaultStmtCacheSize is the number of statements kept by a StmtCache when
+// the size is not given.
+const DefaultStmtCacheSize = 64
+
+// Preparer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Preparer interface {
+	PrepareContext(ctx Context, query string) (*sql.Stmt, error)
+}
+
+// StmtCache is an Executable which prepares statements and reuses them by
+// the final query text. Least recently used statements are closed when the
+// cache is full. Use Tx to issue cached statements in a transaction.
+//
+//	cache := seacle.NewStmtCache(db, 100)
+//	defer cache.Close()
+//	err := seacle.Select(ctx, cache, &people, `WHERE id = ?`, id)
+type StmtCache struct {
+	Executable
+	prep Preparer
+	size int
+
+	mu    sync.Mutex
+	lru   *list.List // of *stmtEntry, the front is the most recent
+	items map[string]*list.Element
+}
+
+type stmtEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int  // number of callers about to use stmt
+	evicted bool // stmt is closed when refs reaches 0
+}
+
+// NewStmtCache returns a StmtCache which keeps at most size statements
+// prepared on e (DefaultStmtCacheSize if size <= 0). When e cannot prepare
+// statements, queries are passed to e as they are.
+func NewStmtCache(e Executable, size int) *StmtCache {
+	if size <= 0 {
+		size = DefaultStmtCacheSize
+	}
+	return &StmtCache{
+		Executable: e,
+		prep:       preparerOf(e),
+		size:       size,
+		lru:        list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+// preparerOf finds a Preparer in s and handles wrapped by s. Handles made
+// by seacle's With* helpers only carry settings, so it is safe to prepare
+// statements on the inner handle.
+func preparerOf(s Selectable) Preparer {
+	for s != nil {
+		if p, ok := s.(Preparer); ok {
+			return p
+		}
+		w, ok := s.(handleWrapper)
+		if !ok {
+			break
+		}
+		s = w.unwrap()
+	}
+	return nil
+}
+
+// uncached returns the handle which s issues statements through, skipping
+// statement caches, for statements not worth caching (e.g. EXPLAIN).
+func uncached(s Selectable) Selectable {
+	for w := s; w != nil; {
+		switch v := w.(type) {
+		case *StmtCache:
+			return v.Executable
+		case *txStmtCache:
+			return v.tx
+		}
+		hw, ok := w.(handleWrapper)
+		if !ok {
+			break
+		}
+		w = hw.unwrap()
+	}
+	return s
+}
+
+func (c *StmtCache) unwrap() Selectable {
+	return c.Executable
+}
+
+// Len returns the number of cached statements.
+func (c *StmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// acquire returns the cached entry for query, preparing it if needed. The
+// entry must be released once its statement is used, so that it is not
+// closed by eviction meanwhile.
+func (c *StmtCache) acquire(ctx Context, query string) (*stmtEntry, error) {
+	c.mu.Lock()
+	if elem, ok := c.items[query]; ok {
+		c.lru.MoveToFront(elem)
+		entry := elem.Value.(*stmtEntry)
+		entry.refs++
+		c.mu.Unlock()
+		return entry, nil
+	}
+	c.mu.Unlock()
+
+	// prepare without the lock not to block other queries
+	stmt, err := c.prep.PrepareContext(ctx, query)
+	if err != nil {
+		c.invalidateOn(err)
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.items[query]; ok {
+		// prepared by another goroutine meanwhile
+		c.lru.MoveToFront(elem)
+		entry := elem.Value.(*stmtEntry)
+		entry.refs++
+		c.mu.Unlock()
+		stmt.Close()
+		return entry, nil
+	}
+	entry := &stmtEntry{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.lru.PushFront(entry)
+	unused := []*sql.Stmt{}
+	for c.lru.Len() > c.size {
+		if v := c.evict(c.lru.Back()); v != nil {
+			unused = append(unused, v)
+		}
+	}
+	c.mu.Unlock()
+
+	// statements still running are closed by database/sql after they finish
+	for _, v := range unused {
+		v.Close()
+	}
+	return entry, nil
+}
+
+// evict removes elem from the cache, and returns its statement if nobody is
+// about to use it. c.mu must be held.
+func (c *StmtCache) evict(elem *list.Element) *sql.Stmt {
+	entry := c.lru.Remove(elem).(*stmtEntry)
+	delete(c.items, entry.query)
+	entry.evicted = true
+	if entry.refs > 0 {
+		return nil
+	}
+	return entry.stmt
+}
+
+// release ends the use of entry, and closes its statement if it has been
+// evicted meanwhile.
+func (c *StmtCache) release(entry *stmtEntry) {
+	c.mu.Lock()
+	entry.refs--
+	unused := entry.evicted && entry.refs == 0
+	c.mu.Unlock()
+	if unused {
+		entry.stmt.Close()
+	}
+}
+
+// database/sql does not export these errors.
+const (
+	errDBClosed   = "sql: database is closed"
+	errStmtClosed = "sql: statement is closed"
+)
+
+// isStmtClosed reports whether err is of a statement closed by database/sql
+// itself, e.g. at the end of the transaction it was prepared in. The cache
+// never closes statements in use.
+func isStmtClosed(err error) bool {
+	return err != nil && err.Error() == errStmtClosed
+}
+
+// invalidateOn drops all statements when err means that the handle they were
+// prepared on is gone: the connection is lost, the transaction has ended or
+// the database is closed.
+func (c *StmtCache) invalidateOn(err error) {
+	if errors.Is(err, sql.ErrConnDone) || errors.Is(err, sql.ErrTxDone) || errors.Is(err, driver.ErrBadConn) ||
+		err.Error() == errDBClosed || isStmtClosed(err) {
+		c.Close()
+	}
+}
+
+// Close closes all cached statements. The underlying handle is not closed,
+// and the cache is still usable.
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	stmts := make([]*sql.Stmt, 0, c.lru.Len())
+	for c.lru.Len() > 0 {
+		if v := c.evict(c.lru.Front()); v != nil {
+			stmts = append(stmts, v)
+		}
+	}
+	c.mu.Unlock()
+
+	var err error
+	for _, v := range stmts {
+		if cerr := v.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// run calls f with the statement for query. When the statement has been
+// closed under the cache, it is prepared again once, so that the error of the
+// handle (e.g. sql.ErrTxDone) is told instead.
+func (c *StmtCache) run(ctx Context, query string, f func(stmt *sql.Stmt) error) error {
+	for retried := false; ; retried = true {
+		entry, err := c.acquire(ctx, query)
+		if err != nil {
+			return err
+		}
+		err = f(entry.stmt)
+		c.release(entry)
+		if err == nil {
+			return nil
+		}
+		c.invalidateOn(err)
+		if retried || !isStmtClosed(err) {
+			return err
+		}
+	}
+}
+
+func (c *StmtCache) QueryContext(ctx Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if c.prep == nil {
+		return c.Executable.QueryContext(ctx, query, args...)
+	}
+	var rows *sql.Rows
+	err := c.run(ctx, query, func(stmt *sql.Stmt) (err error) {
+		// rows keep the statement open by themselves
+		rows, err = stmt.QueryContext(ctx, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (c *StmtCache) QueryRowContext(ctx Context, query string, args ...interface{}) *sql.Row {
+	if c.prep == nil {
+		return c.Executable.QueryRowContext(ctx, query, args...)
+	}
+	var row *sql.Row
+	c.run(ctx, query, func(stmt *sql.Stmt) error {
+		row = stmt.QueryRowContext(ctx, args...)
+		return row.Err()
+	})
+	if row == nil || isStmtClosed(row.Err()) {
+		// *sql.Row cannot be made with an error, so let the handle tell it
+		return c.Executable.QueryRowContext(ctx, query, args...)
+	}
+	return row
+}
+
+func (c *StmtCache) ExecContext(ctx Context, query string, args ...interface{}) (sql.Result, error) {
+	if c.prep == nil {
+		return c.Executable.ExecContext(ctx, query, args...)
+	}
+	var result sql.Result
+	err := c.run(ctx, query, func(stmt *sql.Stmt) (err error) {
+		result, err = stmt.ExecContext(ctx, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Tx returns an Executable which issues statements of the cache in tx.
+// Statements are rebound to tx by tx.StmtContext, which reuses the one
+// already prepared on the connection of tx, and kept until tx ends. tx must
+// be begun on the handle of the cache, and the result should be used for all
+// statements in tx.
+func (c *StmtCache) Tx(tx *sql.Tx) Executable {
+	return &txStmtCache{
+		cache: c,
+		tx:    tx,
+		stmts: map[string]*sql.Stmt{},
+	}
+}
+
+type txStmtCache struct {
+	cache *StmtCache
+	tx    *sql.Tx
+
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt // bound to tx
+}
+
+// unwrap returns the cache so that settings of handles wrapped by the cache
+// are visible.
+func (t *txStmtCache) unwrap() Selectable {
+	return t.cache
+}
+
+// stmt returns the statement for query bound to tx. It is closed by
+// database/sql when tx ends, and keeps the statement of the cache open until
+// then.
+func (t *txStmtCache) stmt(ctx Context, query string) (*sql.Stmt, error) {
+	t.mu.Lock()
+	stmt, ok := t.stmts[query]
+	t.mu.Unlock()
+	if ok {
+		return stmt, nil
+	}
+
+	entry, err := t.cache.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	stmt = t.tx.StmtContext(ctx, entry.stmt)
+	t.cache.release(entry)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if v, ok := t.stmts[query]; ok {
+		// bound by another goroutine meanwhile
+		stmt.Close()
+		return v, nil
+	}
+	t.stmts[query] = stmt
+	return stmt, nil
+}
+
+// run calls f with the statement for query bound to tx. Statements are
+// closed when tx ends, then it binds the statement again once, so that
+// sql.ErrTxDone is told instead.
+func (t *txStmtCache) run(ctx Context, query string, f func(stmt *sql.Stmt) error) error {
+	for retried := false; ; retried = true {
+		stmt, err := t.stmt(ctx, query)
+		if err != nil {
+			return err
+		}
+		err = f(stmt)
+		if retried || !isStmtClosed(err) {
+			return err
+		}
+		t.mu.Lock()
+		t.stmts = map[string]*sql.Stmt{}
+		t.mu.Unlock()
+	}
+}
+
+func (t *txStmtCache) QueryContext(ctx Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if t.cache.prep == nil {
+		return t.tx.QueryContext(ctx, query, args...)
+	}
+	var rows *sql.Rows
+	err := t.run(ctx, query, func(stmt *sql.Stmt) (err error) {
+		rows, err = stmt.QueryContext(ctx, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (t *txStmtCache) QueryRowContext(ctx Context, query string, args ...interface{}) *sql.Row {
+	if t.cache.prep == nil {
+		return t.tx.QueryRowContext(ctx, query, args...)
+	}
+	var row *sql.Row
+	t.run(ctx, query, func(stmt *sql.Stmt) error {
+		row = stmt.QueryRowContext(ctx, args...)
+		return row.Err()
+	})
+	if row == nil || isStmtClosed(row.Err()) {
+		return t.tx.QueryRowContext(ctx, query, args...)
+	}
+	return row
+}
+
+func (t *txStmtCache) ExecContext(ctx Context, query string, args ...interface{}) (sql.Result, error) {
+	if t.cache.prep == nil {
+		return t.tx.ExecContext(ctx, query, args...)
+	}
+	var result sql.Result
+	err := t.run(ctx, query, func(stmt *sql.Stmt) (err error) {
+		result, err = stmt.ExecContext(ctx, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
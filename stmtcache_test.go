@@ -0,0 +1,209 @@
+package seacle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// preparer counts prepared statements.
+type preparer struct {
+	*sql.Conn
+	prepared []string
+}
+
+func (p *preparer) PrepareContext(ctx Context, query string) (*sql.Stmt, error) {
+	p.prepared = append(p.prepared, query)
+	return p.Conn.PrepareContext(ctx, query)
+}
+
+func TestStmtCache(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	p := &preparer{Conn: conn}
+	cache := NewStmtCache(WithDialect(p, SQLite), 2)
+	defer cache.Close()
+
+	fragments := []string{`WHERE id = ?`, `WHERE name = ?`, `WHERE id = ?`, `WHERE id > ?`, `WHERE name = ?`}
+	for _, v := range fragments {
+		people := []*Person{}
+		err := Select(ctx, cache, &people, v, 1)
+		if err != nil {
+			t.Fatalf("failed to select: %s", err)
+		}
+	}
+	// "WHERE name = ?" was evicted by "WHERE id > ?"
+	if len(p.prepared) != 4 || cache.Len() != 2 {
+		t.Errorf("unexpected prepared statements: %v", p.prepared)
+	}
+	// dialect of the wrapped handle is used
+	if p.prepared[0] != `SELECT "person"."id", "person"."name", "person"."created_at" FROM "person" WHERE id = ?` {
+		t.Errorf("unexpected query: %s", p.prepared[0])
+	}
+
+	count, err := Count(ctx, cache, &Person{}, `WHERE id = ?`, 1)
+	if err != nil || count != 1 {
+		t.Errorf("unexpected count: %d, %v", count, err)
+	}
+	err = Update(ctx, cache, &Person{ID: 9999})
+	if err != nil {
+		t.Errorf("failed to update: %s", err)
+	}
+	if len(p.prepared) != 6 || cache.Len() != 2 {
+		t.Errorf("unexpected prepared statements: %v", p.prepared)
+	}
+
+	// invalidated with the connection
+	conn.Close()
+	person := &Person{}
+	err = SelectRow(ctx, cache, person, `WHERE id = ?`, 1)
+	if !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("statements are not invalidated: %d", cache.Len())
+	}
+}
+
+func TestStmtCacheTx(t *testing.T) {
+	ctx := context.Background()
+	cache := NewStmtCache(db, 0)
+	defer cache.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin: %s", err)
+	}
+	defer tx.Rollback()
+	h := cache.Tx(tx)
+
+	p := &Person{Name: "Cecilia", CreatedAt: time.Now()}
+	p.ID, err = Insert(ctx, h, p)
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		person := &Person{}
+		err = SelectRow(ctx, h, person, `WHERE id = ?`, p.ID)
+		if err != nil || person.Name != "Cecilia" {
+			t.Fatalf("unexpected person: %v, %v", person, err)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("unexpected number of statements: %d", cache.Len())
+	}
+	// bound to tx once for each query
+	if n := len(h.(*txStmtCache).stmts); n != 2 {
+		t.Errorf("unexpected number of statements in tx: %d", n)
+	}
+
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("failed to rollback: %s", err)
+	}
+	// statements of the cache survive the transaction
+	person := &Person{}
+	err = SelectRow(ctx, cache, person, `WHERE id = ?`, p.ID)
+	if err != sql.ErrNoRows {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = SelectRow(ctx, h, person, `WHERE id = ?`, p.ID)
+	if !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	people := []*Person{}
+	err = Select(ctx, h, &people, `WHERE id = ?`, p.ID)
+	if !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, err = Insert(ctx, h, p)
+	if !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStmtCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+
+	// prepared in a transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin: %s", err)
+	}
+	defer tx.Rollback()
+	cache := NewStmtCache(tx, 0)
+	people := []*Person{}
+	err = Select(ctx, cache, &people, `WHERE id = ?`, 1)
+	if err != nil || cache.Len() != 1 {
+		t.Fatalf("failed to select: %d, %v", cache.Len(), err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf("failed to commit: %s", err)
+	}
+	err = Select(ctx, cache, &people, `WHERE id = ?`, 1)
+	if !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("statements are not invalidated: %d", cache.Len())
+	}
+
+	// prepared on a closed database
+	memdb, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open: %s", err)
+	}
+	cache = NewStmtCache(memdb, 0)
+	_, err = cache.ExecContext(ctx, `SELECT 1`)
+	if err != nil || cache.Len() != 1 {
+		t.Fatalf("failed to exec: %d, %v", cache.Len(), err)
+	}
+	memdb.Close()
+	_, err = cache.ExecContext(ctx, `SELECT 1`)
+	if err == nil || err.Error() != "sql: database is closed" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("statements are not invalidated: %d", cache.Len())
+	}
+}
+
+func TestStmtCacheConcurrent(t *testing.T) {
+	ctx := context.Background()
+	cache := NewStmtCache(db, 1)
+	defer cache.Close()
+
+	// every query evicts the statement other goroutines are about to use
+	fragments := []string{`WHERE id = ?`, `WHERE id >= ?`, `WHERE id <= ?`}
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 32)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 300; j++ {
+				people := []*Person{}
+				err := Select(ctx, cache, &people, fragments[(i+j)%len(fragments)], 1)
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("failed to select: %s", err)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("unexpected number of statements: %d", cache.Len())
+	}
+}
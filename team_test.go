@@ -0,0 +1,109 @@
+package seacle
+
+import (
+	"database/sql"
+)
+
+// Team has a unique column besides the primary key.
+type Team struct {
+	ID   int64  `db:"id,primary"`
+	Name string `db:"name"`
+}
+
+func (p *Team) Table() string {
+	return "team"
+}
+
+func (p *Team) Columns() []string {
+	return []string{"team.id", "team.name"}
+}
+
+func (p *Team) PrimaryKeys() []string {
+	return []string{"id"}
+}
+
+func (p *Team) PrimaryValues() []interface{} {
+	return []interface{}{p.ID}
+}
+
+func (p *Team) ValueColumns() []string {
+	return []string{"name"}
+}
+
+func (p *Team) Values() []interface{} {
+	return []interface{}{p.Name}
+}
+
+func (p *Team) AutoIncrementColumn() string {
+	return ""
+}
+
+func (p *Team) Scan(r RowScanner) error {
+	var arg0 int64
+	var arg1 string
+
+	err := r.Scan(&arg0, &arg1)
+	if err == sql.ErrNoRows {
+		return err
+	} else if err != nil {
+		// something wrong
+		return err
+	}
+
+	p.ID = arg0
+	p.Name = arg1
+
+	return nil
+}
+
+// Player references Team.
+type Player struct {
+	ID     int64 `db:"id,primary,auto_increment"`
+	TeamID int64 `db:"team_id"`
+}
+
+func (p *Player) Table() string {
+	return "player"
+}
+
+func (p *Player) Columns() []string {
+	return []string{"player.id", "player.team_id"}
+}
+
+func (p *Player) PrimaryKeys() []string {
+	return []string{"id"}
+}
+
+func (p *Player) PrimaryValues() []interface{} {
+	return []interface{}{p.ID}
+}
+
+func (p *Player) ValueColumns() []string {
+	return []string{"team_id"}
+}
+
+func (p *Player) Values() []interface{} {
+	return []interface{}{p.TeamID}
+}
+
+func (p *Player) AutoIncrementColumn() string {
+	return "id"
+}
+
+func (p *Player) Scan(r RowScanner) error {
+	var arg0 int64
+	var arg1 int64
+
+	err := r.Scan(&arg0, &arg1)
+	if err == sql.ErrNoRows {
+		return err
+	} else if err != nil {
+		// something wrong
+		return err
+	}
+
+	p.ID = arg0
+	p.TeamID = arg1
+
+	return nil
+}
@@ -22,4 +22,5 @@ type TestPerson2 struct {
 type TestPerson3 struct {
 	TestPerson
 	SerialID uuid.UUID `db:"uuid"`
+	Password string    `db:"password,secret"`
 }
@@ -0,0 +1,76 @@
+package seacle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Attribute keys set on spans, following the OpenTelemetry semantic
+// conventions for databases.
+const (
+	AttrStatement = "db.statement"
+	AttrOperation = "db.operation"
+	AttrTable     = "db.sql.table"
+)
+
+// Tracer starts a Span for each statement issued by seacle. It is small
+// enough to be adapted to any tracing library (see the otelseacle package for
+// OpenTelemetry).
+type Tracer interface {
+	Start(ctx Context, name string) (Context, Span)
+}
+
+// Span is a traced statement.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// spanKey is the context key of the span started by hook. Each tracerHook
+// has its own key, so that nested tracers do not see spans of each other.
+type spanKey struct {
+	hook *tracerHook
+}
+
+type tracerHook struct {
+	tracer Tracer
+}
+
+// TracingHook returns a Hook which traces statements by tr. Install it by
+// AddHook for every handle, or use WithTracer for a handle.
+func TracingHook(tr Tracer) Hook {
+	return &tracerHook{tracer: tr}
+}
+
+// WithTracer returns an Executable which traces statements issued through it
+// by tr.
+func WithTracer(e Executable, tr Tracer) Executable {
+	return WithHooks(e, TracingHook(tr))
+}
+
+func (h *tracerHook) BeforeQuery(ctx Context, ev *QueryEvent) Context {
+	name := ev.Op
+	if ev.Table != "" {
+		name += " " + ev.Table
+	}
+	ctx, span := h.tracer.Start(ctx, name)
+	span.SetAttribute(AttrStatement, ev.Query)
+	span.SetAttribute(AttrOperation, ev.Op)
+	if ev.Table != "" {
+		span.SetAttribute(AttrTable, ev.Table)
+	}
+	return context.WithValue(ctx, spanKey{hook: h}, span)
+}
+
+func (h *tracerHook) AfterQuery(ctx Context, ev *QueryEvent) {
+	span, ok := ctx.Value(spanKey{hook: h}).(Span)
+	if !ok {
+		return
+	}
+	if ev.Err != nil && !errors.Is(ev.Err, sql.ErrNoRows) {
+		span.RecordError(ev.Err)
+	}
+	span.End()
+}
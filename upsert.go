@@ -0,0 +1,206 @@
+package seacle
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type upsertOptions struct {
+	update []string
+	keep   []string
+}
+
+// UpsertOption changes which columns Upsert overwrites on conflict.
+type UpsertOption func(o *upsertOptions)
+
+// UpdateColumns makes Upsert overwrite only cols on conflict.
+func UpdateColumns(cols ...string) UpsertOption {
+	return func(o *upsertOptions) {
+		o.update = append(o.update, cols...)
+	}
+}
+
+// KeepColumns makes Upsert leave cols as they are on conflict.
+func KeepColumns(cols ...string) UpsertOption {
+	return func(o *upsertOptions) {
+		o.keep = append(o.keep, cols...)
+	}
+}
+
+// updateColumns returns ValueColumns() of in filtered by opts.
+func (o *upsertOptions) updateColumns(in Modifiable) ([]string, error) {
+	valueCols := in.ValueColumns()
+	known := make(map[string]bool, len(valueCols))
+	for _, v := range valueCols {
+		known[v] = true
+	}
+	for _, v := range append(o.update, o.keep...) {
+		if !known[v] {
+			return nil, fmt.Errorf("unknown value column: %s", v)
+		}
+	}
+
+	cols := valueCols
+	if len(o.update) != 0 {
+		cols = o.update
+	}
+	keep := make(map[string]bool, len(o.keep))
+	for _, v := range o.keep {
+		keep[v] = true
+	}
+	updates := make([]string, 0, len(cols))
+	for _, v := range cols {
+		if !keep[v] {
+			updates = append(updates, v)
+		}
+	}
+	return updates, nil
+}
+
+// Upsert inserts in, or updates the existing row when it conflicts on
+// PrimaryKeys(). All ValueColumns() are overwritten unless opts say otherwise.
+// The auto increment column is written when it has a value, because it is a
+// part of the conflict target. When it is zero, the column is omitted like
+// Insert and a new row gets a generated ID. On MySQL, such a row may still
+// conflict on another unique key, and then the existing row is updated.
+// PostgreSQL and SQLite report an error for it instead, since the conflict
+// target is PrimaryKeys() only.
+// It returns the auto increment ID of the row: the generated or updated one,
+// or the one in already had.
+func Upsert(ctx Context, e Executable, in Modifiable, opts ...UpsertOption) (int64, error) {
+	o := &upsertOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	updates, err := o.updateColumns(in)
+	if err != nil {
+		return 0, fmt.Errorf("Upsert: %s", err)
+	}
+
+	d := dialectOf(e)
+	clause, err := d.OnConflictUpdate(quoteNames(d, in.PrimaryKeys()), quoteNames(d, updates))
+	if err != nil {
+		return 0, fmt.Errorf("Upsert: %s", err)
+	}
+
+	columns := in.PrimaryKeys()
+	columns = append(columns, in.ValueColumns()...)
+	args := in.PrimaryValues()
+	args = append(args, in.Values()...)
+
+	// zero in the auto increment column means a new row
+	autoIncrementCol := in.AutoIncrementColumn()
+	var id int64
+	generated := false
+	for i, v := range columns {
+		if autoIncrementCol != "" && v == autoIncrementCol {
+			n, ok := toInt64(args[i])
+			id, generated = n, ok && n == 0
+			break
+		}
+	}
+	if generated {
+		columns, args = insertColumns(in)
+		if u, ok := d.(upsertIDer); ok {
+			if v := u.upsertInsertID(quoteName(d, autoIncrementCol)); v != "" {
+				clause += ", " + v
+			}
+		}
+	}
+
+	q := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (?) %s`, quoteName(d, in.Table()), strings.Join(quoteNames(d, columns), ","), clause)
+	returning := generated && d.SupportsReturning()
+	if returning {
+		// the driver may not support LastInsertId (e.g. PostgreSQL)
+		q = fmt.Sprintf("%s RETURNING %s", q, quoteName(d, autoIncrementCol))
+	}
+	query, exargs, err := expandPlaceholder(d, q, args)
+	if err != nil {
+		return 0, fmt.Errorf("Upsert: %w", err)
+	}
+	query = annotate(ctx, d, query)
+
+	redacted := redactColumns(in, columns, exargs)
+	ctx, hooks := startQuery(ctx, e, "Upsert", in.Table(), query, exargs, redacted)
+	if returning {
+		err = e.QueryRowContext(ctx, query, exargs...).Scan(&id)
+		if err != nil {
+			err = formatError("Upsert", in.Table(), "QueryRowContext returned error", query, redacted, err)
+			hooks.finish(-1, err)
+			return 0, err
+		}
+		hooks.finish(1, nil)
+		return id, nil
+	}
+
+	result, err := e.ExecContext(ctx, query, exargs...)
+	if err != nil {
+		err = formatError("Upsert", in.Table(), "ExecContext returned error", query, redacted, err)
+		hooks.finish(-1, err)
+		return 0, err
+	}
+	if generated {
+		id, err = result.LastInsertId()
+		if err != nil {
+			err = formatError("Upsert", in.Table(), "Failed to get LastInsertId", query, redacted, err)
+			hooks.finish(-1, err)
+			return 0, err
+		}
+	}
+	hooks.finish(rowsAffected(result), nil)
+	return id, nil
+}
+
+// toInt64 converts v of an integer type to int64. nil is regarded as zero.
+func toInt64(v interface{}) (int64, bool) {
+	if v == nil {
+		return 0, true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+// InsertIgnore inserts in unless it conflicts with an existing row, and
+// reports whether the row was actually written. Only conflicts on unique keys
+// are ignored. On MySQL, the duplicated row is not reported as written unless
+// the connection sets CLIENT_FOUND_ROWS.
+func InsertIgnore(ctx Context, e Executable, in Modifiable) (bool, error) {
+	d := dialectOf(e)
+	clause, err := d.InsertIgnore(quoteNames(d, in.PrimaryKeys()))
+	if err != nil {
+		return false, fmt.Errorf("InsertIgnore: %s", err)
+	}
+
+	columns, args := insertColumns(in)
+	q := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (?) %s`, quoteName(d, in.Table()), strings.Join(quoteNames(d, columns), ","), clause)
+	query, exargs, err := expandPlaceholder(d, q, args)
+	if err != nil {
+		return false, fmt.Errorf("InsertIgnore: %w", err)
+	}
+	query = annotate(ctx, d, query)
+
+	redacted := redactColumns(in, columns, exargs)
+	ctx, hooks := startQuery(ctx, e, "InsertIgnore", in.Table(), query, exargs, redacted)
+	result, err := e.ExecContext(ctx, query, exargs...)
+	if err != nil {
+		err = formatError("InsertIgnore", in.Table(), "ExecContext returned error", query, redacted, err)
+		hooks.finish(-1, err)
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		err = formatError("InsertIgnore", in.Table(), "Failed to get RowsAffected", query, redacted, err)
+		hooks.finish(-1, err)
+		return false, err
+	}
+	hooks.finish(affected, nil)
+	return affected > 0, nil
+}
@@ -0,0 +1,242 @@
+package seacle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOnConflictUpdate(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		keys     []string
+		updates  []string
+		expected string
+	}{
+		{MySQL, []string{"`id`"}, []string{"`name`", "`created_at`"}, "ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `created_at` = VALUES(`created_at`)"},
+		{MySQL, []string{"`id`"}, nil, "ON DUPLICATE KEY UPDATE `id` = `id`"},
+		{PostgreSQL, []string{`"id"`, `"name"`}, []string{`"uuid"`}, `ON CONFLICT ("id", "name") DO UPDATE SET "uuid" = excluded."uuid"`},
+		{SQLite, []string{`"id"`}, nil, `ON CONFLICT ("id") DO NOTHING`},
+	}
+
+	for _, tt := range tests {
+		clause, err := tt.dialect.OnConflictUpdate(tt.keys, tt.updates)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.dialect.Name(), err)
+		}
+		if clause != tt.expected {
+			t.Errorf("%s: unexpected clause: %s", tt.dialect.Name(), clause)
+		}
+	}
+
+	_, err := Generic.OnConflictUpdate([]string{"id"}, nil)
+	if err == nil || err.Error() != "generic does not support upsert" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+
+	h := WithDialect(conn, SQLite)
+	tm, _ := time.Parse("2006-01-02 15:04:05", "2020-07-10 10:00:00")
+	person := &Person{
+		ID:        100,
+		Name:      "Elidibus",
+		CreatedAt: tm,
+	}
+	defer Delete(ctx, conn, person)
+
+	// insert
+	id, err := Upsert(ctx, h, person)
+	if err != nil {
+		t.Fatalf("failed to upsert: %s", err)
+	}
+	if id != 100 {
+		t.Errorf("unexpected id: %d", id)
+	}
+
+	// update all value columns
+	person.Name = "Themis"
+	_, err = Upsert(ctx, h, person)
+	if err != nil {
+		t.Fatalf("failed to upsert: %s", err)
+	}
+	fetched := &Person{}
+	err = SelectRow(ctx, conn, fetched, `WHERE id = ?`, 100)
+	if err != nil {
+		t.Fatalf("failed to fetch: %s", err)
+	}
+	if fetched.Name != "Themis" {
+		t.Errorf("unexpected name: %s", fetched.Name)
+	}
+
+	// keep name
+	person.Name = "Emperor"
+	person.CreatedAt = tm.Add(time.Hour)
+	_, err = Upsert(ctx, h, person, KeepColumns("name"))
+	if err != nil {
+		t.Fatalf("failed to upsert: %s", err)
+	}
+	err = SelectRow(ctx, conn, fetched, `WHERE id = ?`, 100)
+	if err != nil {
+		t.Fatalf("failed to fetch: %s", err)
+	}
+	if fetched.Name != "Themis" {
+		t.Errorf("unexpected name: %s", fetched.Name)
+	}
+	if fetched.CreatedAt.Unix() != tm.Add(time.Hour).Unix() {
+		t.Errorf("unexpected created_at: %s", fetched.CreatedAt)
+	}
+
+	// update only name
+	person.CreatedAt = tm
+	_, err = Upsert(ctx, h, person, UpdateColumns("name"))
+	if err != nil {
+		t.Fatalf("failed to upsert: %s", err)
+	}
+	err = SelectRow(ctx, conn, fetched, `WHERE id = ?`, 100)
+	if err != nil {
+		t.Fatalf("failed to fetch: %s", err)
+	}
+	if fetched.Name != "Emperor" || fetched.CreatedAt.Unix() != tm.Add(time.Hour).Unix() {
+		t.Errorf("unexpected row: %v", fetched)
+	}
+
+	// fail (unknown column)
+	_, err = Upsert(ctx, h, person, KeepColumns("age"))
+	if err == nil || err.Error() != "Upsert: unknown value column: age" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// fail (unsupported dialect)
+	_, err = Upsert(ctx, conn, person)
+	if err == nil || err.Error() != "Upsert: generic does not support upsert" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUpsertNewRows(t *testing.T) {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin: %s", err)
+	}
+	defer tx.Rollback()
+
+	tm, _ := time.Parse("2006-01-02 15:04:05", "2020-07-10 10:00:00")
+	rec := &recorder{Executable: tx}
+	for _, d := range []Dialect{SQLite, returningSQLite} {
+		h := WithDialect(rec, d)
+		ids := []int64{}
+		for _, name := range []string{"Lahabrea", "Igeyorhm"} {
+			id, err := Upsert(ctx, h, &Person{Name: name, CreatedAt: tm})
+			if err != nil {
+				t.Fatalf("%s: failed to upsert: %s", d.Name(), err)
+			}
+			fetched := &Person{}
+			err = SelectRow(ctx, tx, fetched, `WHERE id = ?`, id)
+			if err != nil || fetched.Name != name {
+				t.Errorf("%s: unexpected row: %v, %v", d.Name(), fetched, err)
+			}
+			ids = append(ids, id)
+		}
+		if ids[0] == 0 || ids[0] == ids[1] {
+			t.Errorf("%s: unexpected ids: %v", d.Name(), ids)
+		}
+
+		// the returned ID updates the row
+		id, err := Upsert(ctx, h, &Person{ID: ids[1], Name: "Nabriales", CreatedAt: tm})
+		if err != nil || id != ids[1] {
+			t.Fatalf("%s: failed to upsert: %d, %v", d.Name(), id, err)
+		}
+		count, err := Count(ctx, tx, &Person{}, `WHERE id IN (?)`, ids)
+		if err != nil || count != 2 {
+			t.Errorf("%s: unexpected count: %d, %v", d.Name(), count, err)
+		}
+	}
+
+	expected := []string{
+		`INSERT INTO "person" ("name","created_at") VALUES (?,?) ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name", "created_at" = excluded."created_at"`,
+		`INSERT INTO "person" ("id","name","created_at") VALUES (?,?,?) ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name", "created_at" = excluded."created_at"`,
+		`INSERT INTO "person" ("name","created_at") VALUES (?,?) ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name", "created_at" = excluded."created_at" RETURNING "id"`,
+	}
+	for i, j := range []int{0, 2, 3} {
+		if rec.queries[j] != expected[i] {
+			t.Errorf("unexpected query: %s", rec.queries[j])
+		}
+	}
+
+	// MySQL may update a row conflicting on another unique key
+	rec.queries = nil
+	Upsert(ctx, WithDialect(rec, MySQL), &Person{Name: "Hythlodaeus", CreatedAt: tm})
+	if len(rec.queries) != 1 || rec.queries[0] != "INSERT INTO `person` (`name`,`created_at`) VALUES (?,?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `created_at` = VALUES(`created_at`), `id` = LAST_INSERT_ID(`id`)" {
+		t.Errorf("unexpected queries: %v", rec.queries)
+	}
+}
+
+func TestInsertIgnore(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to checkout connection: %s", err.Error())
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS event (id VARCHAR(40) PRIMARY KEY, payload TEXT)`)
+	if err != nil {
+		t.Fatalf("failed to create table: %s", err)
+	}
+	defer conn.ExecContext(ctx, `DROP TABLE event`)
+
+	rec := &recorder{Executable: conn}
+	h := WithDialect(rec, SQLite)
+	ev := &Event{ID: "ev-1", Payload: "first"}
+	written, err := InsertIgnore(ctx, h, ev)
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+	if !written {
+		t.Errorf("first event is not written")
+	}
+	if rec.queries[0] != `INSERT INTO "event" ("id","payload") VALUES (?,?) ON CONFLICT DO NOTHING` {
+		t.Errorf("unexpected query: %s", rec.queries[0])
+	}
+
+	// redelivered
+	ev.Payload = "second"
+	written, err = InsertIgnore(ctx, h, ev)
+	if err != nil {
+		t.Fatalf("failed to insert: %s", err)
+	}
+	if written {
+		t.Errorf("duplicated event is written")
+	}
+
+	var payload string
+	err = conn.QueryRowContext(ctx, `SELECT payload FROM event WHERE id = ?`, "ev-1").Scan(&payload)
+	if err != nil {
+		t.Fatalf("failed to fetch: %s", err)
+	}
+	if payload != "first" {
+		t.Errorf("unexpected payload: %s", payload)
+	}
+
+	clause, err := MySQL.InsertIgnore([]string{"`id`"})
+	if clause != "ON DUPLICATE KEY UPDATE `id` = `id`" || err != nil {
+		t.Errorf("unexpected result: %s, %v", clause, err)
+	}
+	clause, err = PostgreSQL.InsertIgnore([]string{`"id"`})
+	if clause != "ON CONFLICT DO NOTHING" || err != nil {
+		t.Errorf("unexpected result: %s, %v", clause, err)
+	}
+	_, err = InsertIgnore(ctx, conn, ev)
+	if err == nil || err.Error() != "InsertIgnore: generic does not support insert-or-ignore" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}